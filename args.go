@@ -0,0 +1,298 @@
+package errlog
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+//Param is an argument of a stack trace item, paired with the parameter of the function signature
+type Param struct {
+	Name  string //Name of the parameter, "_" if unnamed
+	Type  string //Type of the parameter, as written in the source
+	Value string //Decoded value (eg: 42, true, 0xc000010000, <string len 5>), "?" if it cannot be decoded
+}
+
+var (
+	//parsedFiles caches source files parsed for decoding args and finding return statements, by path
+	parsedFiles   = make(map[string]*ast.File)
+	parsedFilesMu sync.Mutex
+	parsedFset    = token.NewFileSet() //positions of parsedFiles
+)
+
+//decodeArgs pairs the raw words of item.Args with the parameters of the function, found in its source file.
+//Decoding stops at the first parameter whose size is unknown (structs, arrays, floats, named types...). It returns
+//nil if the function declaration cannot be found (eg: for func literals) or if the function is generic.
+func decodeArgs(item StackTraceItem) []Param {
+	decl := findFuncDecl(item)
+	if decl == nil || decl.Type.TypeParams != nil || strings.Contains(item.CallingObject, "[") {
+		return nil
+	}
+
+	var fields []*ast.Field
+	if decl.Recv != nil {
+		fields = append(fields, decl.Recv.List...)
+	}
+	fields = append(fields, decl.Type.Params.List...)
+
+	words := item.Args
+	var params []Param
+	for _, field := range fields {
+		typ := exprString(field.Type)
+		names := []string{"_"}
+		if len(field.Names) > 0 {
+			names = names[:0]
+			for _, name := range field.Names {
+				names = append(names, name.Name)
+			}
+		}
+
+		for _, name := range names {
+			value, size := decodeWords(field.Type, words)
+			if size == 0 {
+				return append(params, Param{Name: name, Type: typ, Value: "?"})
+			}
+			params = append(params, Param{Name: name, Type: typ, Value: value})
+			words = words[size:]
+		}
+	}
+
+	return params
+}
+
+//decodeWords decodes the first words of the value of a parameter of type typ. It returns the decoded value ("?" if
+//the runtime flagged one of its words as possibly inaccurate) and how many words it used, or 0 if the value cannot
+//be decoded.
+func decodeWords(typ ast.Expr, words []string) (string, int) {
+	var size int
+	switch t := typ.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			size = 2
+		case "error", "any":
+			size = 2
+		case "bool", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+			size = 1
+		}
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType:
+		size = 1
+	case *ast.ArrayType:
+		if t.Len == nil {
+			size = 3
+		}
+	case *ast.Ellipsis:
+		size = 3
+	case *ast.InterfaceType:
+		size = 2
+	case *ast.SelectorExpr:
+		if exprString(t) == "unsafe.Pointer" {
+			size = 1
+		}
+	}
+	if size == 0 || len(words) < size {
+		return "", 0
+	}
+	for _, word := range words[:size] {
+		if strings.HasSuffix(word, "?") { // the runtime is not sure of this word, decoding it would mislead
+			return "?", size
+		}
+	}
+
+	value, ok := decodeValue(typ, words[:size])
+	if !ok {
+		return "", 0
+	}
+	return value, size
+}
+
+//decodeValue decodes the words of the value of a parameter of type typ
+func decodeValue(typ ast.Expr, words []string) (string, bool) {
+	n := make([]uint64, len(words))
+	for i := range n {
+		v, err := strconv.ParseUint(strings.TrimPrefix(words[i], "0x"), 16, 64)
+		if err != nil {
+			return "", false
+		}
+		n[i] = v
+	}
+
+	switch t := typ.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return fmt.Sprintf("<string len %d>", n[1]), true
+		case "error", "any":
+			if n[0] == 0 {
+				return "nil", true
+			}
+			return fmt.Sprintf("<%s>", t.Name), true
+		case "bool":
+			return strconv.FormatBool(n[0] != 0), true
+		case "int", "int64":
+			return strconv.FormatInt(int64(n[0]), 10), true
+		case "int8":
+			return strconv.FormatInt(int64(int8(n[0])), 10), true
+		case "int16":
+			return strconv.FormatInt(int64(int16(n[0])), 10), true
+		case "int32", "rune":
+			return strconv.FormatInt(int64(int32(n[0])), 10), true
+		}
+		return strconv.FormatUint(n[0], 10), true
+	case *ast.ArrayType, *ast.Ellipsis:
+		return fmt.Sprintf("<%s len %d cap %d>", exprString(typ), n[1], n[2]), true
+	case *ast.InterfaceType:
+		if n[0] == 0 {
+			return "nil", true
+		}
+		return "<interface>", true
+	}
+
+	//pointers
+	if n[0] == 0 {
+		return "nil", true
+	}
+	return fmt.Sprintf("%#x", n[0]), true
+}
+
+//formatCall returns the name of the function of item, followed by its decoded params if any (eg: main.f(id=42, name=<string len 5>))
+func formatCall(item StackTraceItem) string {
+	if len(item.Params) == 0 {
+		return item.CallingObject
+	}
+
+	params := make([]string, len(item.Params))
+	for i, p := range item.Params {
+		params[i] = p.Name + "=" + p.Value
+	}
+	return item.CallingObject + "(" + strings.Join(params, ", ") + ")"
+}
+
+//findFuncDecl finds the declaration of the function of item in its source file
+func findFuncDecl(item StackTraceItem) *ast.FuncDecl {
+	recv, name := splitFuncName(item.CallingObject)
+	if name == "" {
+		return nil
+	}
+
+	file := parseSourceFile(item.SourcePathRef)
+	if file == nil {
+		return nil
+	}
+
+	for _, d := range file.Decls {
+		decl, ok := d.(*ast.FuncDecl)
+		if !ok || decl.Name.Name != name {
+			continue
+		}
+		if recv == "" && decl.Recv == nil {
+			return decl
+		}
+		if recv != "" && decl.Recv != nil && len(decl.Recv.List) == 1 && receiverTypeName(decl.Recv.List[0].Type) == recv {
+			return decl
+		}
+	}
+
+	return nil
+}
+
+//splitFuncName returns the receiver type name and the name of a function as printed in stack traces
+//(eg: "Store", "Save" for main.(*Store).Save). The name is empty for func literals (eg: main.main.func1).
+func splitFuncName(function string) (recv, name string) {
+	function = regexpFingerprintGeneric.ReplaceAllString(function, "")
+	_, function = splitPackage(function)
+
+	parts := strings.Split(function, ".")
+	switch len(parts) {
+	case 1:
+		name = parts[0]
+	case 2:
+		recv, name = strings.Trim(parts[0], "(*)"), parts[1]
+	default:
+		return "", ""
+	}
+	if strings.HasPrefix(name, "func") && strings.TrimLeft(name[4:], "0123456789") == "" {
+		return "", ""
+	}
+	return recv, name
+}
+
+//receiverTypeName returns the name of the type of a receiver (eg: Store for *Store or *Store[K])
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+//parseSourceFile parses path, or returns it from cache. It returns nil if the file cannot be read or parsed.
+//Positions of the file are the ones of parsedFset.
+func parseSourceFile(path string) *ast.File {
+	parsedFilesMu.Lock()
+	defer parsedFilesMu.Unlock()
+
+	if file, ok := parsedFiles[path]; ok {
+		return file
+	}
+
+	var file *ast.File
+	if src, err := afero.ReadFile(fs, path); err == nil {
+		file, _ = parser.ParseFile(parsedFset, path, src, parser.SkipObjectResolution)
+	}
+	parsedFiles[path] = file
+
+	return file
+}
+
+//exprString returns the source code of a type expression
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + exprString(t.Elt)
+		}
+		return "[" + exprString(t.Len) + "]" + exprString(t.Elt)
+	case *ast.Ellipsis:
+		return "..." + exprString(t.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(t.Key) + "]" + exprString(t.Value)
+	case *ast.ChanType:
+		return "chan " + exprString(t.Value)
+	case *ast.FuncType:
+		return "func(...)"
+	case *ast.InterfaceType:
+		return "interface{...}"
+	case *ast.StructType:
+		return "struct{...}"
+	case *ast.BasicLit:
+		return t.Value
+	case *ast.IndexExpr:
+		return exprString(t.X) + "[" + exprString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		indices := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			indices[i] = exprString(index)
+		}
+		return exprString(t.X) + "[" + strings.Join(indices, ", ") + "]"
+	}
+	return "?"
+}
@@ -0,0 +1,191 @@
+package errlog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultAsyncWorkers   = 1
+	defaultAsyncQueueSize = 256
+)
+
+//DropPolicy tells what an async logger does with a report when its queue is full
+type DropPolicy int
+
+const (
+	//DropNewest discards the report being debugged (default)
+	DropNewest DropPolicy = iota
+	//DropOldest discards the oldest queued report to make room for the new one
+	DropOldest
+	//Block waits for room in the queue, slowing down the caller of Debug
+	Block
+)
+
+//AsyncConfig configures the async mode of a logger (see Config.Async).
+//In async mode, Debug only captures the stack trace and formats named values, which must be done on the goroutine of
+//the caller. Loading sources, rendering, printing and delivering to sinks are done by a pool of workers.
+//Reports keep the time of the Debug call. If Config.Exporter is set, the report is exported on the goroutine of the
+//caller too, so that the events are added to its span while it is still recording.
+//Call Flush or Close before exiting, or queued reports are lost.
+type AsyncConfig struct {
+	Workers    int        //How many reports are processed concurrently (0 means 1). Printed reports are never interleaved.
+	QueueSize  int        //How many reports can wait for a worker (0 means 256)
+	DropPolicy DropPolicy //What to do with reports when the queue is full
+}
+
+//Stats counts the reports of a logger
+type Stats struct {
+	Delivered uint64 //Reports processed (printed and sent to sinks)
+	Dropped   uint64 //Reports discarded because the async queue was full (see DropPolicy)
+	Pending   int    //Reports waiting in the async queue or being processed
+}
+
+//asyncJob is a report waiting for a worker, either still to build or already built and exported
+type asyncJob struct {
+	ctx       context.Context
+	err       error
+	stLines   []StackTraceItem
+	createdBy []StackTraceItem
+	vars      []Var
+	at        time.Time //when Debug was called
+
+	report *Report //built and exported on the caller, only to print and send to sinks
+}
+
+//asyncQueue runs the reports of a logger on a pool of workers
+type asyncQueue struct {
+	config AsyncConfig
+	jobs   chan asyncJob
+
+	closeMu sync.RWMutex //held for writing when closing jobs, for reading when sending to it
+	closed  bool
+
+	mu      sync.Mutex
+	pending int
+	idle    chan struct{} //closed when pending is 0
+
+	dropped uint64 //atomic
+	workers sync.WaitGroup
+}
+
+//newAsyncQueue starts the workers of a queue processing jobs with process
+func newAsyncQueue(cfg AsyncConfig, process func(asyncJob)) *asyncQueue {
+	if cfg.Workers < 1 {
+		cfg.Workers = defaultAsyncWorkers
+	}
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = defaultAsyncQueueSize
+	}
+
+	q := &asyncQueue{
+		config: cfg,
+		jobs:   make(chan asyncJob, cfg.QueueSize),
+		idle:   make(chan struct{}),
+	}
+	close(q.idle)
+
+	q.workers.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go func() {
+			defer q.workers.Done()
+			for job := range q.jobs {
+				process(job)
+				q.done()
+			}
+		}()
+	}
+
+	return q
+}
+
+//enqueue queues job, applying the drop policy if the queue is full. It returns false if the queue is closed.
+func (q *asyncQueue) enqueue(job asyncJob) bool {
+	q.closeMu.RLock()
+	defer q.closeMu.RUnlock()
+	if q.closed {
+		return false
+	}
+
+	q.add()
+	switch q.config.DropPolicy {
+	case Block:
+		q.jobs <- job
+	case DropOldest:
+		for {
+			select {
+			case q.jobs <- job:
+				return true
+			default:
+			}
+			select {
+			case <-q.jobs:
+				atomic.AddUint64(&q.dropped, 1)
+				q.done()
+			default:
+			}
+		}
+	default:
+		select {
+		case q.jobs <- job:
+		default:
+			atomic.AddUint64(&q.dropped, 1)
+			q.done()
+		}
+	}
+	return true
+}
+
+//add counts a pending job
+func (q *asyncQueue) add() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == 0 {
+		q.idle = make(chan struct{})
+	}
+	q.pending++
+}
+
+//done counts a job as processed or dropped
+func (q *asyncQueue) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending--
+	if q.pending == 0 {
+		close(q.idle)
+	}
+}
+
+//flush waits until every queued job is processed, or until ctx is done
+func (q *asyncQueue) flush(ctx context.Context) error {
+	q.mu.Lock()
+	idle := q.idle
+	q.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//close processes the queued jobs and stops the workers
+func (q *asyncQueue) close() {
+	q.closeMu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.closeMu.Unlock()
+	q.workers.Wait()
+}
+
+//stats returns the dropped and pending counts of the queue
+func (q *asyncQueue) stats() (dropped uint64, pending int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return atomic.LoadUint64(&q.dropped), q.pending
+}
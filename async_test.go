@@ -0,0 +1,94 @@
+package errlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+//blockingSink blocks in Send until release is closed
+type blockingSink struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSink) Send(r *Report) error {
+	s.started <- struct{}{}
+	<-s.release
+	return nil
+}
+
+func TestAsyncDropNewest(t *testing.T) {
+	sink := &blockingSink{started: make(chan struct{}, 10), release: make(chan struct{})}
+	l := NewLogger(&Config{
+		PrintFunc: func(format string, data ...interface{}) {},
+		Sink:      sink,
+		Async:     &AsyncConfig{Workers: 1, QueueSize: 1, DropPolicy: DropNewest},
+	}).(*logger)
+	defer l.Close()
+
+	start := time.Now()
+	l.Debug(errors.New("first")) // taken by the worker, which blocks
+	<-sink.started
+	l.Debug(errors.New("second")) // queued
+	l.Debug(errors.New("third"))  // dropped
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Debug blocked for %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Flush() = %v while blocked, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(sink.release)
+	if err := l.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := l.Stats(); stats != (Stats{Delivered: 2, Dropped: 1}) {
+		t.Errorf("Stats() = %+v, want 2 delivered and 1 dropped", stats)
+	}
+}
+
+func TestAsyncReportTime(t *testing.T) {
+	for _, exporter := range []*MemoryExporter{nil, {}} {
+		sink := &blockingSink{started: make(chan struct{}, 10), release: make(chan struct{})}
+		aggregator := NewAggregator()
+		config := &Config{
+			PrintFunc:  func(format string, data ...interface{}) {},
+			Sink:       sink,
+			Aggregator: aggregator,
+			Async:      &AsyncConfig{Workers: 1},
+		}
+		if exporter != nil {
+			config.Exporter = exporter
+		}
+		l := NewLogger(config).(*logger)
+
+		l.Debug(errors.New("first")) // taken by the worker, which blocks
+		<-sink.started
+		before := time.Now()
+		l.Debug(errors.New("second")) // queued
+		after := time.Now()
+
+		if exporter != nil {
+			if events := exporter.Events(); len(events) != 2 {
+				t.Errorf("%d events exported when Debug returned, want 2", len(events))
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		close(sink.release)
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, g := range aggregator.Snapshot() {
+			if g.Sample.Message == "second" && (g.Sample.Time.Before(before) || g.Sample.Time.After(after)) {
+				t.Errorf("exporter %v: report time = %s, want the time of the Debug call, between %s and %s", exporter != nil, g.Sample.Time, before, after)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package errlog
+
+import (
+	"errors"
+	"testing"
+)
+
+// Compare the cost of Debug with a plain error check:
+//
+//	go test -run '^$' -bench .                  # nil errors, disabled and enabled modes
+//	go test -run '^$' -bench . -tags errlog_off # Debug compiled to err != nil (no disabled mode)
+//
+//BenchmarkDebugNil and BenchmarkDebugEnabled run with both, to compare with BenchmarkIfErrNotNil.
+
+var (
+	benchErr    = errors.New("bench")
+	benchResult bool
+)
+
+func BenchmarkIfErrNotNil(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		benchResult = benchErr != nil
+	}
+}
+
+func BenchmarkDebugNil(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		benchResult = Debug(nil)
+	}
+}
+
+func BenchmarkDebugDisabled(b *testing.B) {
+	if debugCompiledOut {
+		b.Skip("modes do not apply with errlog_off")
+	}
+	defer DefaultLogger.Disable(false)
+	DefaultLogger.Disable(true)
+	for i := 0; i < b.N; i++ {
+		benchResult = Debug(benchErr)
+	}
+}
+
+//BenchmarkDebugEnabled debugs a non-nil error, reported without printing (with errlog_off, Debug only checks it)
+func BenchmarkDebugEnabled(b *testing.B) {
+	defer func(cfg *Config) { DefaultLogger.SetConfig(cfg) }(DefaultLogger.Config())
+	DefaultLogger.SetConfig(&Config{PrintFunc: func(format string, data ...interface{}) {}, PrintError: true})
+	for i := 0; i < b.N; i++ {
+		benchResult = Debug(benchErr)
+	}
+}
@@ -0,0 +1,35 @@
+//Command errlog provides tools around errlog reports.
+//
+//	errlog render [-markdown] [panic.log]
+//	errlog symbolize -binary ./app [-markdown] [crash.log]
+//
+//render prints the stack trace of a panic log with source excerpts, like errlog.Debug would.
+//
+//symbolize resolves the addresses of a crash log (PCs, func+0x offsets or a goroutine stack trace) with the
+//symbol table of the binary which crashed, and prints the stack trace with source excerpts.
+//
+//With -markdown, the report is written as Markdown, ready to be pasted into an issue or a pull request.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+var commands = map[string]func(args []string) error{
+	"render":    render,
+	"symbolize": symbolize,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprintln(os.Stderr, "usage: errlog render [-markdown] [file]")
+		fmt.Fprintln(os.Stderr, "       errlog symbolize -binary <path> [-markdown] [file]")
+		os.Exit(2)
+	}
+
+	if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "errlog:", err)
+		os.Exit(1)
+	}
+}
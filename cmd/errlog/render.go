@@ -0,0 +1,21 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/snwfdhmp/errlog"
+)
+
+//render prints the stack trace of a panic log read from a file or stdin
+func render(args []string) error {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	report := addReportFlags(flags)
+	flags.Parse(args)
+
+	text, err := readInput(flags)
+	if err != nil {
+		return err
+	}
+
+	return report.print(text, errlog.ParseStack(string(text)))
+}
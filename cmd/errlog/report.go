@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/snwfdhmp/errlog"
+)
+
+var regexpPanicMessage = regexp.MustCompile(`(?m)^(?:panic|fatal error): (.+)$`)
+
+//reportFlags are the flags of the commands printing a report
+type reportFlags struct {
+	markdown    *bool
+	linesBefore *int
+	linesAfter  *int
+}
+
+//addReportFlags defines the report flags on flags
+func addReportFlags(flags *flag.FlagSet) *reportFlags {
+	return &reportFlags{
+		markdown:    flags.Bool("markdown", false, "write the report as Markdown, for issues and pull requests"),
+		linesBefore: flags.Int("lines-before", 4, "lines printed before the failing line"),
+		linesAfter:  flags.Int("lines-after", 2, "lines printed after the failing line"),
+	}
+}
+
+//print prints a report of the crash described by text, with the given stack trace
+func (f *reportFlags) print(text []byte, items []errlog.StackTraceItem) error {
+	items = trimPanicFrames(items)
+	if len(items) == 0 {
+		return errors.New("no stack trace found")
+	}
+
+	message := "stack trace"
+	if m := regexpPanicMessage.FindSubmatch(text); m != nil {
+		message = string(m[1])
+	}
+
+	cfg := &errlog.Config{
+		PrintFunc:        errlog.DefaultLoggerPrintFunc,
+		LinesBefore:      *f.linesBefore,
+		LinesAfter:       *f.linesAfter,
+		PrintError:       true,
+		PrintSource:      true,
+		PrintStackSource: true,
+		StackFilter:      &errlog.StackFilter{HideRuntime: true},
+	}
+
+	if *f.markdown {
+		return errlog.NewMarkdownRenderer(cfg).Render(os.Stdout, errlog.NewReport(errors.New(message), items))
+	}
+	errlog.NewLogger(cfg).(errlog.StackDebugger).DebugStack(errors.New(message), items)
+	return nil
+}
+
+//trimPanicFrames removes the frames of the runtime raising the panic, so that the first frame is the failing code
+func trimPanicFrames(items []errlog.StackTraceItem) []errlog.StackTraceItem {
+	for len(items) > 1 && (strings.HasPrefix(items[0].CallingObject, "runtime.") || items[0].CallingObject == "panic") {
+		items = items[1:]
+	}
+	return items
+}
+
+//readInput reads the file given as first argument of flags, or stdin
+func readInput(flags *flag.FlagSet) ([]byte, error) {
+	if flags.NArg() == 0 {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(flags.Arg(0))
+}
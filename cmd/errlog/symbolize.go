@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/snwfdhmp/errlog"
+)
+
+//symbolize prints the stack trace of a crash log read from a file or stdin, resolved with the symbols of a binary
+func symbolize(args []string) error {
+	flags := flag.NewFlagSet("symbolize", flag.ExitOnError)
+	binary := flags.String("binary", "", "path of the binary which produced the crash log")
+	report := addReportFlags(flags)
+	flags.Parse(args)
+
+	if *binary == "" {
+		return errors.New("symbolize: -binary is required")
+	}
+
+	text, err := readInput(flags)
+	if err != nil {
+		return err
+	}
+
+	symbolizer, err := errlog.NewSymbolizer(*binary)
+	if err != nil {
+		return err
+	}
+	items, err := symbolizer.SymbolizeText(string(text))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "errlog:", err)
+	}
+
+	return report.print(text, items)
+}
@@ -0,0 +1,31 @@
+//go:build !errlog_off
+
+package errlog
+
+import "context"
+
+//debugCompiledOut tells whether package-level Debug funcs are compiled to err != nil (see the errlog_off build tag)
+const debugCompiledOut = false
+
+//Debug is a shortcut for DefaultLogger.Debug.
+func Debug(uErr error) bool {
+	return DefaultLogger.debugDepth(1, context.Background(), uErr, nil)
+}
+
+//DebugVars is a shortcut for DefaultLogger.DebugVars.
+func DebugVars(uErr error, keyvals ...interface{}) bool {
+	return DefaultLogger.debugDepth(1, context.Background(), uErr, keyvals)
+}
+
+//DebugMap is a shortcut for DefaultLogger.DebugMap.
+func DebugMap(uErr error, vars map[string]interface{}) bool {
+	if uErr == nil {
+		return DefaultLogger.debugDepth(1, context.Background(), uErr, nil)
+	}
+	return DefaultLogger.debugDepth(1, context.Background(), uErr, mapToKeyvals(vars))
+}
+
+//DebugContext is a shortcut for DefaultLogger.DebugContext.
+func DebugContext(ctx context.Context, uErr error) bool {
+	return DefaultLogger.debugDepth(1, ctx, uErr, nil)
+}
@@ -0,0 +1,33 @@
+//go:build errlog_off
+
+package errlog
+
+import "context"
+
+//Built with the errlog_off tag, Debug, DebugVars, DebugMap and DebugContext only tell whether the error is not nil,
+//and are inlined by the compiler: they cost as much as if err != nil. Loggers created with NewLogger still work.
+//
+//	go build -tags errlog_off
+
+//debugCompiledOut tells whether package-level Debug funcs are compiled to err != nil
+const debugCompiledOut = true
+
+//Debug returns whether uErr != nil (built with errlog_off).
+func Debug(uErr error) bool {
+	return uErr != nil
+}
+
+//DebugVars returns whether uErr != nil (built with errlog_off).
+func DebugVars(uErr error, keyvals ...interface{}) bool {
+	return uErr != nil
+}
+
+//DebugMap returns whether uErr != nil (built with errlog_off).
+func DebugMap(uErr error, vars map[string]interface{}) bool {
+	return uErr != nil
+}
+
+//DebugContext returns whether uErr != nil (built with errlog_off).
+func DebugContext(ctx context.Context, uErr error) bool {
+	return uErr != nil
+}
@@ -0,0 +1,84 @@
+//go:build !errlog_off
+
+package errlog
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDebugDepth(t *testing.T) {
+	sink := make(chanSink, 16)
+	defer func(cfg *Config) { DefaultLogger.SetConfig(cfg) }(DefaultLogger.Config())
+	DefaultLogger.SetConfig(&Config{PrintFunc: func(format string, data ...interface{}) {}, Sink: sink})
+
+	for i := 0; i < 3; i++ {
+		Debug(nil) // must not change the depth of the next calls
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			DebugVars(errors.New("concurrent"), "i", i)
+		}()
+	}
+	wg.Wait()
+	Debug(errors.New("last"))
+
+	for i := 0; i < 9; i++ {
+		r := <-sink
+		if len(r.Stack) == 0 || !strings.HasPrefix(r.Stack[0].CallingObject, packagePath+".TestDebugDepth") {
+			t.Fatalf("%q reported from %+v, want TestDebugDepth", r.Message, r.Stack)
+		}
+	}
+}
+
+func TestOverload(t *testing.T) {
+	sink := make(chanSink, 1)
+	l := NewLogger(&Config{PrintFunc: func(format string, data ...interface{}) {}, Sink: sink}).(*logger)
+
+	check := func(err error) bool {
+		l.Overload(1) // reports the caller of check
+		return l.Debug(err)
+	}
+	check(errors.New("failed"))
+
+	if r := <-sink; r.Stack[0].CallingObject != packagePath+".TestOverload" {
+		t.Errorf("reported from %s, want TestOverload", r.Stack[0].CallingObject)
+	}
+	if l.stackDepthOverload != 0 {
+		t.Errorf("depth %d left for the next Debug call", l.stackDepthOverload)
+	}
+}
+
+func TestDebugEnableRulesCache(t *testing.T) {
+	rules, _ := ParseEnableRules("...,-" + packagePath)
+	sink := make(chanSink, 1)
+	defer func(cfg *Config) { DefaultLogger.SetConfig(cfg) }(DefaultLogger.Config())
+	DefaultLogger.SetConfig(&Config{PrintFunc: func(format string, data ...interface{}) {}, Sink: sink, Enable: rules})
+
+	for i := 0; i < 3; i++ {
+		Debug(nil)
+	}
+	if !Debug(errors.New("filtered")) || len(sink) != 0 {
+		t.Fatal("the error of a disabled package was reported")
+	}
+
+	cached := 0
+	rules.cache.Range(func(pc, allowed interface{}) bool {
+		cached++
+		frame, _ := runtime.CallersFrames([]uintptr{pc.(uintptr)}).Next()
+		if frame.Function != packagePath+".TestDebugEnableRulesCache" || allowed.(bool) {
+			t.Errorf("cached %t for %s, want false for TestDebugEnableRulesCache", allowed, frame.Function)
+		}
+		return true
+	})
+	if cached != 1 {
+		t.Errorf("cached %d call sites, want 1", cached)
+	}
+}
@@ -9,15 +9,13 @@ var (
 	}
 
 	//DefaultLogger logger implements default configuration for a logger
-	DefaultLogger = &logger{
-		config: &Config{
-			PrintFunc:          DefaultLoggerPrintFunc,
-			LinesBefore:        4,
-			LinesAfter:         2,
-			PrintStack:         false,
-			PrintSource:        true,
-			PrintError:         true,
-			ExitOnDebugSuccess: false,
-		},
-	}
+	DefaultLogger = newLogger(&Config{
+		PrintFunc:          DefaultLoggerPrintFunc,
+		LinesBefore:        4,
+		LinesAfter:         2,
+		PrintStack:         false,
+		PrintSource:        true,
+		PrintError:         true,
+		ExitOnDebugSuccess: false,
+	})
 )
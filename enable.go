@@ -0,0 +1,100 @@
+package errlog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+//EnableRules decide whether errors are reported, from the package or the file of the Debug call (see Config.Enable).
+//Rules are comma separated patterns, the last one matching a call wins. A pattern prefixed with - disables reports:
+//
+//	github.com/acme/billing/...,-github.com/acme/billing/legacy,cmd/server/main.go
+//
+//A pattern is a package import path, which may end with /... to match its subpackages too (... alone matches every
+//package), or a file ending with .go, matching the files having this path or ending with /pattern.
+//Calls matched by no rule are reported only if there are no enabling rules.
+//
+//The decision is cached per call site, so only the first Debug call of each call site evaluates the rules.
+type EnableRules struct {
+	spec  string
+	rules []enableRule
+	cache sync.Map //map[uintptr]bool, the decision per program counter of Debug calls
+}
+
+//enableRule is a pattern of EnableRules
+type enableRule struct {
+	pattern string
+	disable bool
+}
+
+//ParseEnableRules parses comma separated rules (eg: the value of ERRLOG_ENABLE, see LoadConfig)
+func ParseEnableRules(spec string) (*EnableRules, error) {
+	r := &EnableRules{spec: spec}
+	for _, pattern := range strings.Split(spec, ",") {
+		pattern = strings.TrimSpace(pattern)
+		rule := enableRule{pattern: strings.TrimPrefix(pattern, "-"), disable: strings.HasPrefix(pattern, "-")}
+		if rule.pattern == "" {
+			if pattern != "" {
+				return nil, fmt.Errorf("%q: empty pattern", pattern)
+			}
+			continue
+		}
+		r.rules = append(r.rules, rule)
+	}
+	return r, nil
+}
+
+//String returns the rules as parsed
+func (r *EnableRules) String() string {
+	return r.spec
+}
+
+//Allows tells whether errors debugged from a file of a package are reported
+func (r *EnableRules) Allows(pkg, file string) bool {
+	allowed := true
+	for _, rule := range r.rules {
+		if !rule.disable {
+			allowed = false //with enabling rules, calls matching no rule are not reported
+			break
+		}
+	}
+	for _, rule := range r.rules {
+		if rule.matches(pkg, file) {
+			allowed = !rule.disable
+		}
+	}
+	return allowed
+}
+
+//allowsCaller tells whether errors debugged by the caller skip frames above the caller of allowsCaller are reported
+//(skip is given like to runtime.Caller)
+func (r *EnableRules) allowsCaller(skip int) bool {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return true
+	}
+	if allowed, ok := r.cache.Load(pcs[0]); ok {
+		return allowed.(bool)
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	allowed := r.Allows(packageOf(frame.Function), frame.File)
+	r.cache.Store(pcs[0], allowed)
+	return allowed
+}
+
+//matches tells whether the rule matches a file of a package
+func (rule enableRule) matches(pkg, file string) bool {
+	switch {
+	case strings.HasSuffix(rule.pattern, ".go"):
+		return file == rule.pattern || strings.HasSuffix(file, "/"+rule.pattern)
+	case rule.pattern == "...":
+		return true
+	case strings.HasSuffix(rule.pattern, "/..."):
+		base := strings.TrimSuffix(rule.pattern, "/...")
+		return pkg == base || strings.HasPrefix(pkg, base+"/")
+	}
+	return pkg == rule.pattern
+}
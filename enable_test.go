@@ -0,0 +1,61 @@
+package errlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnableRules(t *testing.T) {
+	rules, err := ParseEnableRules("github.com/acme/billing/...,-github.com/acme/billing/legacy, cmd/server/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		pkg, file string
+		want      bool
+	}{
+		{"github.com/acme/billing", "/src/billing/invoice.go", true},
+		{"github.com/acme/billing/tax", "/src/billing/tax/tax.go", true},
+		{"github.com/acme/billing/legacy", "/src/billing/legacy/old.go", false},
+		{"github.com/acme/billingfoo", "/src/billingfoo/foo.go", false},
+		{"main", "/src/cmd/server/main.go", true},
+		{"main", "/src/cmd/worker/main.go", false},
+	} {
+		if got := rules.Allows(c.pkg, c.file); got != c.want {
+			t.Errorf("Allows(%q, %q) = %t, want %t", c.pkg, c.file, got, c.want)
+		}
+	}
+
+	rules, _ = ParseEnableRules("-github.com/acme/billing/legacy")
+	if !rules.Allows("github.com/acme/shop", "/src/shop/shop.go") || rules.Allows("github.com/acme/billing/legacy", "/src/old.go") {
+		t.Error("disabling rules alone should only disable what they match")
+	}
+
+	if _, err := ParseEnableRules("github.com/acme/billing,-"); err == nil {
+		t.Error("ParseEnableRules() accepted an empty pattern")
+	}
+}
+
+func TestDebugEnableRules(t *testing.T) {
+	for _, c := range []struct {
+		spec    string
+		printed bool
+	}{
+		{"...,-github.com/snwfdhmp/errlog", false},
+		{"github.com/acme/billing/...,enable_test.go", true},
+	} {
+		rules, _ := ParseEnableRules(c.spec)
+		printed := false
+		l := NewLogger(&Config{PrintFunc: func(format string, data ...interface{}) { printed = true }, PrintError: true, Enable: rules})
+
+		for i := 0; i < 2; i++ { // the second call uses the cache
+			printed = false
+			if !l.Debug(errors.New("filtered")) {
+				t.Errorf("%s: Debug() = false, want true", c.spec)
+			}
+			if printed != c.printed {
+				t.Errorf("%s: printed = %t, want %t", c.spec, printed, c.printed)
+			}
+		}
+	}
+}
@@ -64,29 +64,49 @@
 package errlog
 
 import (
-	"github.com/sirupsen/logrus"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+
 	"github.com/spf13/afero"
 )
 
 var (
-	debugMode = false
-	fs        = afero.NewOsFs() //fs is at package level because I think it needn't be scoped to loggers
+	debugMode   = false
+	debugLogger atomic.Pointer[slog.Logger] //receives internal debug messages, nil means stderr
+	fs          = afero.NewOsFs()           //fs is at package level because I think it needn't be scoped to loggers
 )
 
-//SetDebugMode sets debug mode to On if toggle==true or Off if toggle==false. It changes log level an so displays more logs about whats happening. Useful for debugging.
+//SetDebugMode sets debug mode to On if toggle==true or Off if toggle==false. In debug mode, errlog logs more about whats happening
+//(to stderr, or to the logger given to SetDebugLogger). Useful for debugging.
 func SetDebugMode(toggle bool) {
-	if toggle {
-		logrus.SetLevel(logrus.DebugLevel)
-	} else {
-		logrus.SetLevel(logrus.InfoLevel)
-	}
 	debugMode = toggle
 }
 
-//Debug is a shortcut for DefaultLogger.Debug.
-func Debug(uErr error) bool {
-	DefaultLogger.Overload(1) // Prevents from adding this func to the stack trace
-	return DefaultLogger.Debug(uErr)
+//SetDebugLogger sets the logger receiving the debug messages of errlog, at debug level, when debug mode is on.
+//nil means stderr. See the logruserrlog package to send them to logrus.
+func SetDebugLogger(l *slog.Logger) {
+	debugLogger.Store(l)
+}
+
+//debugf logs an internal debug message when debug mode is on
+func debugf(format string, data ...interface{}) {
+	if !debugMode {
+		return
+	}
+	l := debugLogger.Load()
+	if l == nil {
+		l = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	l.Debug(fmt.Sprintf(format, data...))
+}
+
+//Stack returns the parsed stack trace of the caller, innermost frame first.
+//skip is the number of additional frames to remove from the top of the stack (0 means the caller of Stack is the first item).
+func Stack(skip int) []StackTraceItem {
+	return parseStackTrace(1 + skip)
 }
 
 //PrintStack pretty prints the current stack trace
@@ -103,3 +123,13 @@ func PrintRawStack() {
 func PrintStackMinus(depthToRemove int) {
 	DefaultLogger.printStack(parseStackTrace(1 + depthToRemove))
 }
+
+//Flush is a shortcut for DefaultLogger.Flush. It waits until the reports queued in async mode are delivered.
+func Flush(ctx context.Context) error {
+	return DefaultLogger.Flush(ctx)
+}
+
+//Close is a shortcut for DefaultLogger.Close. It delivers queued reports and flushes pending summaries of repeated reports.
+func Close() error {
+	return DefaultLogger.Close()
+}
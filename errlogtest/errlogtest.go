@@ -0,0 +1,190 @@
+// Package errlogtest integrates errlog with the testing package
+//
+// Use Check instead of `if err != nil { t.Fatal(err) }` to get the source excerpt of the line which
+// produced the error in the test output:
+//
+//	func TestSomething(t *testing.T) {
+//		err := doSomething()
+//		errlogtest.Check(t, err)
+//	}
+//
+// Outputs :
+//
+//	--- FAIL: TestSomething (0.00s)
+//	    something_test.go:12: Error in github.com/me/pkg.TestSomething: something failed
+//	        line 11 of /home/me/pkg/something_test.go:11
+//	        10: func TestSomething(t *testing.T) {
+//	        11:     err := doSomething()
+//	        12:     errlogtest.Check(t, err)
+//	        13: }
+package errlogtest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/snwfdhmp/errlog"
+)
+
+var (
+	//Config is the configuration used to build reports. Its PrintFunc is ignored, reports are written to the test log.
+	Config = errlog.Config{
+		LinesBefore: 4,
+		LinesAfter:  2,
+		PrintSource: true,
+		PrintError:  true,
+	}
+
+	//sarifReports collects the reports written by RunWithSARIF, nil when it is not running
+	sarifReports []*errlog.Report
+	sarifMu      sync.Mutex
+
+	registerOnce sync.Once
+)
+
+//registerDebugFuncs makes errlog find the line which produced the error of Check(t, err) and Verify(t, err) like for
+//errlog.Debug(err). It is done on the first report, not when the package is imported, as it applies to every call
+//named Check or Verify for the rest of the process.
+func registerDebugFuncs() {
+	errlog.RegisterDebugFunc("Check", 1)
+	errlog.RegisterDebugFunc("Verify", 1)
+}
+
+//Check fails and stops the test if err is not nil, logging the source excerpt of the line of the test which produced err
+func Check(t testing.TB, err error) {
+	t.Helper()
+	if err == nil {
+		return
+	}
+	t.Fatalf("%s", report(err))
+}
+
+//Verify is like Check, but lets the test continue. It returns whether err is nil.
+func Verify(t testing.TB, err error) bool {
+	t.Helper()
+	if err == nil {
+		return true
+	}
+	t.Errorf("%s", report(err))
+	return false
+}
+
+//CheckPanic fails the test if it panics, logging the source excerpt of the line which panicked. It must be deferred:
+//
+//	defer errlogtest.CheckPanic(t)
+func CheckPanic(t testing.TB) {
+	t.Helper()
+	r := recover()
+	if r == nil {
+		return
+	}
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", r)
+	}
+	t.Fatalf("%s", report(err))
+}
+
+//RunWithSARIF runs the tests of m, then writes the reports of the failed Check, Verify and CheckPanic calls
+//to path as a SARIF log, for CI annotations. Paths are relative to the root of the module. Use it in TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(errlogtest.RunWithSARIF(m, "errlog.sarif"))
+//	}
+func RunWithSARIF(m *testing.M, path string) int {
+	sarifMu.Lock()
+	sarifReports = []*errlog.Report{}
+	sarifMu.Unlock()
+
+	code := m.Run()
+
+	sarifMu.Lock()
+	defer sarifMu.Unlock()
+	if err := writeSARIF(path, sarifReports); err != nil {
+		fmt.Fprintf(os.Stderr, "errlogtest: cannot write SARIF log: %s\n", err)
+		if code == 0 {
+			code = 1
+		}
+	}
+	sarifReports = nil
+
+	return code
+}
+
+//writeSARIF writes reports to path as a SARIF log
+func writeSARIF(path string, reports []*errlog.Report) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	renderer := &errlog.SARIFRenderer{BaseDir: moduleRoot(wd)}
+	if err := renderer.RenderAll(f, reports); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//moduleRoot returns the first parent of dir containing a go.mod file, or dir if there is none.
+//Tests run in the directory of their package, while CI annotations need paths relative to the repository.
+func moduleRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+//report renders the errlog report of err for the caller of Check, Verify or CheckPanic
+func report(err error) string {
+	registerOnce.Do(registerDebugFuncs)
+	stack := trimTestingFrames(errlog.Stack(2)) // skip report and Check/Verify
+
+	sarifMu.Lock()
+	if sarifReports != nil {
+		sarifReports = append(sarifReports, errlog.NewReport(err, stack))
+	}
+	sarifMu.Unlock()
+
+	var b strings.Builder
+	cfg := Config
+	cfg.PrintFunc = func(format string, data ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", data...)
+	}
+	cfg.ExitOnDebugSuccess = false
+	cfg.Mode = errlog.ModeEnabled
+
+	l := errlog.NewLogger(&cfg).(interface {
+		errlog.StackDebugger
+		io.Closer
+	})
+	defer l.Close()
+	l.DebugStack(err, stack)
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+//trimTestingFrames removes the frames of the testing package, of the runtime and of panics from stack
+func trimTestingFrames(stack []errlog.StackTraceItem) []errlog.StackTraceItem {
+	trimmed := make([]errlog.StackTraceItem, 0, len(stack))
+	for _, item := range stack {
+		if strings.HasPrefix(item.CallingObject, "testing.") || strings.HasPrefix(item.CallingObject, "runtime.") || item.CallingObject == "panic" {
+			continue
+		}
+		trimmed = append(trimmed, item)
+	}
+	return trimmed
+}
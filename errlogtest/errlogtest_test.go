@@ -0,0 +1,45 @@
+package errlogtest_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/snwfdhmp/errlog/errlogtest"
+)
+
+//recordingTB records the failures of a test instead of failing it
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func TestVerify(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	if !errlogtest.Verify(tb, nil) || len(tb.failures) != 0 {
+		t.Fatalf("Verify(nil) failed the test: %q", tb.failures)
+	}
+
+	err := errors.New("connection refused")
+	passed := errlogtest.Verify(tb, err)
+	if passed || len(tb.failures) != 1 {
+		t.Fatalf("Verify(err) did not fail the test once: %q", tb.failures)
+	}
+
+	failure := tb.failures[0]
+	for _, want := range []string{"Error in github.com/snwfdhmp/errlog/errlogtest_test.TestVerify: connection refused", `err := errors.New("connection refused")`} {
+		if !strings.Contains(failure, want) {
+			t.Errorf("failure does not contain %q:\n%s", want, failure)
+		}
+	}
+	if strings.Contains(failure, "failing line not found") {
+		t.Errorf("failing line of Verify(tb, err) not found:\n%s", failure)
+	}
+}
@@ -0,0 +1,119 @@
+package errlog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Exporter records reports as span events (eg: OpenTelemetry, see package otelerrlog).
+//ctx is the one given to DebugContext, context.Background() for the other Debug funcs.
+type Exporter interface {
+	Export(ctx context.Context, event Event) error
+}
+
+//Event is a report converted to a span event, following the OpenTelemetry semantic conventions for exceptions
+type Event struct {
+	Name       string      //Name of the event, always "exception"
+	Time       time.Time   //When the error was debugged
+	Attributes []Attribute //Attributes of the event, see NewEvent
+}
+
+//Attribute is an attribute of an Event
+type Attribute struct {
+	Key   string
+	Value interface{} //string or int
+}
+
+//NewEvent converts r to a span event, with the source excerpt of the Debug call limited to the given numbers of lines.
+//Attributes are the OpenTelemetry ones (exception.type, exception.message, exception.stacktrace, code.function.name,
+//code.file.path and code.line.number), plus errlog.fingerprint, errlog.failing_line.number, errlog.failing_line.code,
+//errlog.source.snippet and errlog.var.<name> for each named value, when known.
+func NewEvent(r *Report, linesBefore, linesAfter int) Event {
+	event := Event{Name: "exception", Time: r.Time}
+	add := func(key string, value interface{}) {
+		event.Attributes = append(event.Attributes, Attribute{Key: key, Value: value})
+	}
+
+	add("exception.type", r.ErrorType)
+	add("exception.message", r.Message)
+	add("exception.stacktrace", formatGoStack(r.Stack))
+	add("errlog.fingerprint", r.Fingerprint)
+
+	if len(r.Stack) > 0 {
+		item := r.Stack[0]
+		add("code.function.name", item.CallingObject)
+		add("code.file.path", item.SourcePathRef)
+		add("code.line.number", item.SourceLineRef)
+
+		if ex, err := loadSourceExcerpt(item.SourcePathRef, item.SourceLineRef, max(linesBefore, 0), max(linesAfter, 0)); err == nil {
+			if ex.FailingLine != -1 {
+				add("errlog.failing_line.number", ex.FailingLine+1)
+				add("errlog.failing_line.code", strings.TrimSpace(ex.Lines[ex.FailingLine]))
+			}
+			add("errlog.source.snippet", formatExcerpt(ex))
+		}
+	}
+
+	for _, v := range r.Vars {
+		add("errlog.var."+v.Name, v.Value)
+	}
+
+	return event
+}
+
+//formatGoStack formats stack like the runtime does in panics, innermost frame first
+func formatGoStack(stack []StackTraceItem) string {
+	var b strings.Builder
+	for _, item := range stack {
+		fmt.Fprintf(&b, "%s(...)\n\t%s:%d", item.CallingObject, item.SourcePathRef, item.SourceLineRef)
+		if item.PCOffset >= 0 {
+			fmt.Fprintf(&b, " +%#x", item.PCOffset)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+//formatExcerpt formats the lines of ex like PrintSource, without colors
+func formatExcerpt(ex *SourceExcerpt) string {
+	var b strings.Builder
+	for _, line := range ex.Excerpt() {
+		if line.Number == 0 {
+			b.WriteString("...\n")
+			continue
+		}
+		fmt.Fprintf(&b, "%d: %s\n", line.Number, line.Text)
+	}
+	return b.String()
+}
+
+//MemoryExporter keeps exported events in memory, to test what would be sent to a tracing backend
+type MemoryExporter struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+//Export stores event
+func (e *MemoryExporter) Export(ctx context.Context, event Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+	return nil
+}
+
+//Events returns the exported events, oldest first
+func (e *MemoryExporter) Events() []Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Event(nil), e.events...)
+}
+
+//Reset forgets every exported event
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = nil
+}
@@ -0,0 +1,49 @@
+package errlog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExporter(t *testing.T) {
+	exporter := &MemoryExporter{}
+	l := NewLogger(&Config{
+		PrintFunc:   func(format string, data ...interface{}) {},
+		LinesBefore: 2,
+		LinesAfter:  1,
+		Exporter:    exporter,
+	}).(*logger)
+
+	err := errors.New("export me")
+	l.DebugContext(context.Background(), err)
+
+	events := exporter.Events()
+	if len(events) != 1 {
+		t.Fatalf("exported %d events, want 1", len(events))
+	}
+
+	attributes := make(map[string]interface{})
+	for _, a := range events[0].Attributes {
+		attributes[a.Key] = a.Value
+	}
+	want := map[string]interface{}{
+		"exception.type":             "*errors.errorString",
+		"exception.message":          "export me",
+		"code.function.name":         "github.com/snwfdhmp/errlog.TestExporter",
+		"errlog.failing_line.code":   `err := errors.New("export me")`,
+		"errlog.failing_line.number": attributes["code.line.number"].(int) - 1,
+	}
+	for key, value := range want {
+		if attributes[key] != value {
+			t.Errorf("attribute %s = %v, want %v", key, attributes[key], value)
+		}
+	}
+	if stack, _ := attributes["exception.stacktrace"].(string); !strings.HasPrefix(stack, "github.com/snwfdhmp/errlog.TestExporter(...)\n\t") {
+		t.Errorf("exception.stacktrace does not start with the Debug call:\n%s", stack)
+	}
+	if snippet, _ := attributes["errlog.source.snippet"].(string); !strings.Contains(snippet, "l.DebugContext(context.Background(), err)") {
+		t.Errorf("errlog.source.snippet does not contain the Debug call:\n%s", snippet)
+	}
+}
@@ -0,0 +1,144 @@
+package errlog
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fingerprintStackDepth = 5  //how many top frames are part of a fingerprint
+	fingerprintLength     = 16 //how many hex chars are kept from the fingerprint hash
+	aggregatorRecentSize  = 50 //how many reports an Aggregator keeps for Recent
+)
+
+var (
+	regexpFingerprintUUID    = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	regexpFingerprintHex     = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	regexpFingerprintQuoted  = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`)
+	regexpFingerprintNumber  = regexp.MustCompile(`[0-9]+(?:\.[0-9]+)?`)
+	regexpFingerprintGeneric = regexp.MustCompile(`\[[^\]]*\]`)
+)
+
+//Fingerprint returns a stable group ID for err raised with the given stack trace (innermost frame first).
+//
+//It is made of the error type, the normalized error message (numbers, hex values, UUIDs and quoted strings
+//are replaced by placeholders) and the function names of the top frames. Line numbers are ignored, so the
+//fingerprint of an error does not change when unrelated code is added above its failing line.
+func Fingerprint(err error, frames []StackTraceItem) string {
+	parts := []string{fmt.Sprintf("%T", err)}
+	if err != nil {
+		parts = append(parts, normalizeMessage(err.Error()))
+	}
+	for i := 0; i < fingerprintStackDepth && i < len(frames); i++ {
+		parts = append(parts, regexpFingerprintGeneric.ReplaceAllString(frames[i].CallingObject, "[...]"))
+	}
+
+	sum := sha1.Sum([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])[:fingerprintLength]
+}
+
+//normalizeMessage replaces the variable parts of an error message by placeholders
+func normalizeMessage(msg string) string {
+	msg = regexpFingerprintUUID.ReplaceAllString(msg, "<uuid>")
+	msg = regexpFingerprintHex.ReplaceAllString(msg, "<hex>")
+	msg = regexpFingerprintQuoted.ReplaceAllString(msg, "<str>")
+	msg = regexpFingerprintNumber.ReplaceAllString(msg, "<n>")
+	return msg
+}
+
+//Group holds the occurrences of reports sharing the same fingerprint
+type Group struct {
+	ID        string    //Fingerprint of the reports
+	Count     int       //How many reports have been added to the group
+	FirstSeen time.Time //Time of the first report
+	LastSeen  time.Time //Time of the last report
+	Sample    *Report   //First report of the group
+}
+
+//Aggregator groups reports by fingerprint in memory and keeps the most recent ones. It is safe for concurrent use.
+//Set it as Config.Aggregator to collect every report debugged by a logger.
+type Aggregator struct {
+	mu     sync.Mutex
+	groups map[string]*Group
+	recent []*Report //ring buffer of the last reports
+	next   int       //index of recent where the next report will be stored
+}
+
+//NewAggregator creates an empty Aggregator
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		groups: make(map[string]*Group),
+		recent: make([]*Report, 0, aggregatorRecentSize),
+	}
+}
+
+//Add counts r in its group, creating the group if needed
+func (a *Aggregator) Add(r *Report) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	g, ok := a.groups[r.Fingerprint]
+	if !ok {
+		g = &Group{ID: r.Fingerprint, FirstSeen: r.Time, Sample: r}
+		a.groups[r.Fingerprint] = g
+	}
+	g.Count++
+	if r.Time.After(g.LastSeen) {
+		g.LastSeen = r.Time
+	}
+
+	if len(a.recent) < cap(a.recent) {
+		a.recent = append(a.recent, r)
+	} else {
+		a.recent[a.next] = r
+	}
+	a.next = (a.next + 1) % cap(a.recent)
+}
+
+//Snapshot returns a copy of every group, most recently seen first
+func (a *Aggregator) Snapshot() []Group {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	groups := make([]Group, 0, len(a.groups))
+	for _, g := range a.groups {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].LastSeen.Equal(groups[j].LastSeen) {
+			return groups[i].ID < groups[j].ID
+		}
+		return groups[i].LastSeen.After(groups[j].LastSeen)
+	})
+
+	return groups
+}
+
+//Recent returns the last reports added, most recent first
+func (a *Aggregator) Recent() []*Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	reports := make([]*Report, 0, len(a.recent))
+	for i := 1; i <= len(a.recent); i++ {
+		reports = append(reports, a.recent[(a.next-i+len(a.recent))%len(a.recent)])
+	}
+
+	return reports
+}
+
+//Reset forgets every group and every recent report
+func (a *Aggregator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.groups = make(map[string]*Group)
+	a.recent = a.recent[:0]
+	a.next = 0
+}
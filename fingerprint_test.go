@@ -0,0 +1,100 @@
+package errlog
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	cases := []struct {
+		msg, want string
+	}{
+		{"user 42 not found", "user <n> not found"},
+		{"took 1.5s", "took <n>s"},
+		{"invalid pointer 0xc000010000", "invalid pointer <hex>"},
+		{"order 3f2b8c1e-9d4a-4b6e-8f0a-1c2d3e4f5a6b expired", "order <uuid> expired"},
+		{`open "config.json": no such file`, "open <str>: no such file"},
+		{"unexpected 'x' at 3", "unexpected <str> at <n>"},
+		{"connection refused", "connection refused"},
+	}
+	for _, c := range cases {
+		if got := normalizeMessage(c.msg); got != c.want {
+			t.Errorf("normalizeMessage(%q) = %q, want %q", c.msg, got, c.want)
+		}
+	}
+}
+
+//fingerprintError is an error type other than the one of fmt.Errorf
+type fingerprintError string
+
+func (e fingerprintError) Error() string { return string(e) }
+
+func TestFingerprint(t *testing.T) {
+	stack := []StackTraceItem{
+		{CallingObject: "main.load[...]", SourceLineRef: 12},
+		{CallingObject: "main.main", SourceLineRef: 5},
+	}
+	moved := []StackTraceItem{stack[0], stack[1]}
+	moved[0].SourceLineRef = 40
+	other := []StackTraceItem{{CallingObject: "main.save"}, stack[1]}
+
+	fingerprint := Fingerprint(fmt.Errorf("user %d not found", 42), stack)
+	if len(fingerprint) != fingerprintLength {
+		t.Errorf("Fingerprint() = %q, want %d hex chars", fingerprint, fingerprintLength)
+	}
+
+	cases := []struct {
+		name  string
+		err   error
+		stack []StackTraceItem
+		same  bool
+	}{
+		{"same error", fmt.Errorf("user %d not found", 42), stack, true},
+		{"other number", fmt.Errorf("user %d not found", 7), stack, true},
+		{"other line", fmt.Errorf("user %d not found", 42), moved, true},
+		{"other message", fmt.Errorf("user %d deleted", 42), stack, false},
+		{"other type", fingerprintError("user 42 not found"), stack, false},
+		{"other function", fmt.Errorf("user %d not found", 42), other, false},
+	}
+	for _, c := range cases {
+		if got := Fingerprint(c.err, c.stack); (got == fingerprint) != c.same {
+			t.Errorf("%s: Fingerprint() = %q, same as %q: %t, want %t", c.name, got, fingerprint, got == fingerprint, c.same)
+		}
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	a := NewAggregator()
+	start := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	var reports []*Report
+	for i, id := range []string{"a", "b", "a"} {
+		r := &Report{Fingerprint: id, Time: start.Add(time.Duration(i) * time.Second)}
+		reports = append(reports, r)
+		a.Add(r)
+	}
+
+	groups := a.Snapshot()
+	if len(groups) != 2 || groups[0].ID != "a" || groups[1].ID != "b" {
+		t.Fatalf("Snapshot() = %+v, want groups a then b", groups)
+	}
+	if g := groups[0]; g.Count != 2 || !g.FirstSeen.Equal(start) || !g.LastSeen.Equal(start.Add(2*time.Second)) || g.Sample != reports[0] {
+		t.Errorf("group a = %+v, want 2 reports from the first one", g)
+	}
+
+	if recent := a.Recent(); len(recent) != 3 || recent[0] != reports[2] || recent[2] != reports[0] {
+		t.Errorf("Recent() = %v, want the reports most recent first", recent)
+	}
+	for i := 0; i < aggregatorRecentSize; i++ {
+		a.Add(&Report{Fingerprint: "c", Time: start.Add(time.Hour)})
+	}
+	if recent := a.Recent(); len(recent) != aggregatorRecentSize || recent[len(recent)-1].Fingerprint != "c" {
+		t.Errorf("Recent() kept %d reports, want the last %d", len(recent), aggregatorRecentSize)
+	}
+
+	a.Reset()
+	if len(a.Snapshot()) != 0 || len(a.Recent()) != 0 {
+		t.Error("Reset() kept reports")
+	}
+}
@@ -0,0 +1,137 @@
+package errlog
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//GoGroup runs goroutines like errgroup.Group, but keeps every failure instead of the first one, each with where it
+//happened: errors are located at the return statement which returned them (or at the Go call if the source of the
+//function is unknown), panics at the panicking line, the Go call being kept as the creator of the goroutine. The zero
+//GoGroup is ready to use:
+//
+//	var g errlog.GoGroup
+//	for _, invoice := range invoices {
+//		g.Go(func() error { return send(invoice) })
+//	}
+//	if err := g.Wait(); err != nil {
+//		err.(*errlog.GoGroupError).Render(os.Stderr, errlog.NewTextRenderer(errlog.DefaultLogger.Config(), false))
+//	}
+//
+//Nothing is printed by the GoGroup itself.
+type GoGroup struct {
+	cancel context.CancelCauseFunc //cancels the context of GoGroupWithContext, nil for other groups
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	started  int //how many goroutines were started, to sort failures
+	failures []groupFailure
+}
+
+//groupFailure is the report of a failed goroutine of a GoGroup
+type groupFailure struct {
+	index  int //index of the Go call which started the goroutine
+	report *Report
+}
+
+//GoGroupWithContext returns a GoGroup, and a context derived from ctx which is canceled when a goroutine of the GoGroup fails,
+//or when Wait returns, like errgroup.WithContext
+func GoGroupWithContext(ctx context.Context) (*GoGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &GoGroup{cancel: cancel}, ctx
+}
+
+//Go runs f in a new goroutine, recovering its panics. If it fails, its report is kept and returned by Wait.
+func (g *GoGroup) Go(f func() error) {
+	pcs := callers(1)
+
+	g.mu.Lock()
+	index := g.started
+	g.started++
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		guard(pcs, f, func(err error, stLines, createdBy []StackTraceItem) {
+			report := newReport(err, stLines, nil)
+			report.CreatedBy = createdBy
+
+			g.mu.Lock()
+			g.failures = append(g.failures, groupFailure{index: index, report: report})
+			g.mu.Unlock()
+
+			if g.cancel != nil {
+				g.cancel(err)
+			}
+		})()
+	}()
+}
+
+//Wait waits for every goroutine started with Go, and returns a *GoGroupError with the reports of the failed ones, or nil
+func (g *GoGroup) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(context.Canceled)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.failures) == 0 {
+		return nil
+	}
+
+	sort.Slice(g.failures, func(i, j int) bool { return g.failures[i].index < g.failures[j].index })
+	reports := make([]*Report, len(g.failures))
+	for i, f := range g.failures {
+		reports[i] = f.report
+	}
+	return &GoGroupError{Reports: reports}
+}
+
+//GoGroupError is the error returned by GoGroup.Wait. It joins the errors of the failed goroutines like errors.Join, so
+//errors.Is and errors.As look into each of them.
+type GoGroupError struct {
+	Reports []*Report //Report of each failed goroutine, in the order of the Go calls which started them
+}
+
+//Error returns the messages of the errors, one per line
+func (e *GoGroupError) Error() string {
+	messages := make([]string, len(e.Reports))
+	for i, r := range e.Reports {
+		messages[i] = r.Message
+	}
+	return strings.Join(messages, "\n")
+}
+
+//Unwrap returns the errors of the failed goroutines
+func (e *GoGroupError) Unwrap() []error {
+	errs := make([]error, len(e.Reports))
+	for i, r := range e.Reports {
+		errs[i] = r.Err
+	}
+	return errs
+}
+
+//Render writes each failure as its own section, with its source excerpt, using renderer (eg: a TextRenderer or a
+//MarkdownRenderer). With a SARIFRenderer, every failure is a result of a single SARIF log.
+func (e *GoGroupError) Render(w io.Writer, renderer Renderer) error {
+	if s, ok := renderer.(*SARIFRenderer); ok {
+		return s.RenderAll(w, e.Reports)
+	}
+
+	for i, r := range e.Reports {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := renderer.Render(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package errlog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGoGroup(t *testing.T) {
+	g, ctx := GoGroupWithContext(context.Background())
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+
+	g.Go(func() error { <-ctx.Done(); return errFirst }) // fails after the second one
+	g.Go(func() error { return nil })
+	g.Go(func() error { return errSecond })
+	g.Go(func() error { <-ctx.Done(); panic("late") })
+
+	err := g.Wait()
+	if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) || !errors.Is(context.Cause(ctx), errSecond) {
+		t.Fatalf("Wait() = %v, cause %v", err, context.Cause(ctx))
+	}
+
+	var groupErr *GoGroupError
+	if !errors.As(err, &groupErr) || len(groupErr.Reports) != 3 {
+		t.Fatalf("Wait() = %#v, want a *GoGroupError with 3 reports", err)
+	}
+	if err.Error() != "first\nsecond\npanic: late" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	for i, function := range []string{"func1", "func3"} {
+		if r := groupErr.Reports[i]; r.Stack[0].CallingObject != packagePath+".TestGoGroup."+function || len(r.CreatedBy) == 0 {
+			t.Errorf("report %d located in %s, want the func given to Go with its creator", i, r.Stack[0].CallingObject)
+		}
+	}
+	if panicked := groupErr.Reports[2]; panicked.Stack[0].CallingObject != packagePath+".TestGoGroup.func4" || len(panicked.CreatedBy) == 0 {
+		t.Errorf("panic located in %s, want the func given to Go with its creator", panicked.Stack[0].CallingObject)
+	}
+
+	var b strings.Builder
+	if err := groupErr.Render(&b, &MarkdownRenderer{LinesBefore: 2, LinesAfter: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if sections := strings.Count(b.String(), "### Error in"); sections != 3 {
+		t.Errorf("rendered %d sections, want 3:\n%s", sections, b.String())
+	}
+	if !strings.Contains(b.String(), "**Goroutine created by**") {
+		t.Errorf("rendered panic without its creator:\n%s", b.String())
+	}
+}
+
+func TestGoGroupNoFailure(t *testing.T) {
+	var g GoGroup
+	g.Go(func() error { return nil })
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+}
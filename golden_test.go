@@ -0,0 +1,130 @@
+package errlog
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/spf13/afero"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+const (
+	//fixturesDir is where fixture sources are referenced by the captured stack traces of testdata
+	fixturesDir = "/src/fixtures/"
+	//fixturesDepth is the depth given by errlog.Debug to parseStackTrace when the stacks were captured
+	fixturesDepth = 2
+)
+
+//fixtures are the names of the testdata cases: <name>.go is a source file, <name>.stack is the stack trace
+//captured when running it, and <name>.golden is the expected output
+var fixtures = []string{
+	"closure",   // Debug called from an inlined func literal
+	"generic",   // Debug called from a generic func
+	"method",    // Debug called from a method with a pointer receiver
+	"multiline", // Debug and failing calls spanning multiple lines
+}
+
+func TestGolden(t *testing.T) {
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			got := renderFixture(t, name)
+
+			goldenPath := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(goldenPath, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("%s (run 'go test -update' to create golden files)", err)
+			}
+			if got != string(want) {
+				t.Errorf("output does not match %s (run 'go test -update' if the change is expected)\n--- got:\n%s\n--- want:\n%s", goldenPath, got, want)
+			}
+		})
+	}
+}
+
+//renderFixture parses the stack trace of fixture name and debugs an error with it, using the fixture source
+func renderFixture(t *testing.T, name string) string {
+	source, err := os.ReadFile(filepath.Join("testdata", name+".go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stack, err := os.ReadFile(filepath.Join("testdata", name+".stack"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memFs := afero.NewMemMapFs()
+	if err := afero.WriteFile(memFs, fixturesDir+name+".go", source, 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(osFs afero.Fs, noColor bool) {
+		fs, color.NoColor = osFs, noColor
+	}(fs, color.NoColor)
+	fs, color.NoColor = memFs, true
+
+	var b strings.Builder
+
+	stLines := parseAnyStackTrace(string(stack), fixturesDepth)
+	b.WriteString("-- stack --\n")
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(stLines); err != nil {
+		t.Fatal(err)
+	}
+
+	b.WriteString("-- report --\n")
+	l := NewLogger(&Config{
+		PrintFunc: func(format string, data ...interface{}) {
+			fmt.Fprintf(&b, format+"\n", data...)
+		},
+		LinesBefore: 4,
+		LinesAfter:  2,
+		PrintError:  true,
+		PrintSource: true,
+		PrintStack:  true,
+
+		DecodeStackArgs: true,
+	}).(*logger)
+	l.DebugStack(errors.New(name+" fixture failed"), stLines)
+	for _, item := range stLines {
+		if item.Params != nil {
+			t.Fatalf("DebugStack decoded the args of %s in the stack trace it was given", item.CallingObject)
+		}
+	}
+
+	b.WriteString("-- stack source --\n")
+	l.SetConfig(&Config{
+		PrintFunc:        l.Config().PrintFunc,
+		PrintStackSource: true,
+		StackSourceLines: 1,
+	})
+	l.DebugStack(errors.New(name+" fixture failed"), stLines)
+
+	b.WriteString("-- markdown --\n")
+	md := &MarkdownRenderer{LinesBefore: 4, LinesAfter: 2}
+	if err := md.Render(&b, NewReport(errors.New(name+" fixture failed"), stLines)); err != nil {
+		t.Fatal(err)
+	}
+
+	b.WriteString("-- sarif --\n")
+	sarif := &SARIFRenderer{BaseDir: "/src"}
+	if err := sarif.Render(&b, NewReport(errors.New(name+" fixture failed"), stLines)); err != nil {
+		t.Fatal(err)
+	}
+
+	return b.String()
+}
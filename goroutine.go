@@ -0,0 +1,256 @@
+package errlog
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxCreatedByDepth = 64
+)
+
+//ErrGroup is a group of goroutines, such as errgroup.Group or GoGroup
+type ErrGroup interface {
+	Go(f func() error)
+}
+
+//PanicError is the error of a goroutine started with Go which panicked
+type PanicError struct {
+	Value interface{} //Value given to panic
+}
+
+//Error returns the panic value as printed by the runtime (eg: panic: runtime error: index out of range)
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+//Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+//Go is a shortcut for DefaultLogger.Go. It runs fn in a new goroutine, debugging the error it returns, and recovering its
+//panics, which are reported with source excerpts of both the failing goroutine and the code which started it:
+//
+//	errlog.Go(func() error {
+//		return syncInvoices(ctx)
+//	})
+func Go(fn func() error) {
+	DefaultLogger.spawn(1, nil, fn)
+}
+
+//GoErrGroup is a shortcut for DefaultLogger.GoErrGroup. It is like Go, with fn run by g (eg: an errgroup.Group),
+//which gets the error of fn, or a *PanicError if it panicked.
+func GoErrGroup(g ErrGroup, fn func() error) {
+	DefaultLogger.spawn(1, g, fn)
+}
+
+//Go runs fn in a new goroutine, reporting the error it returns and recovering its panics (see Go)
+func (l *logger) Go(fn func() error) {
+	l.spawn(1, nil, fn)
+}
+
+//GoErrGroup is like Go, with fn run by g (see GoErrGroup)
+func (l *logger) GoErrGroup(g ErrGroup, fn func() error) {
+	l.spawn(1, g, fn)
+}
+
+//spawn runs fn with g, or in a new goroutine if g is nil, reporting its failure (see guard) with the stack trace of the
+//caller skip frames above the caller of spawn
+func (l *logger) spawn(skip int, g ErrGroup, fn func() error) {
+	run := guard(callers(skip+1), fn, func(err error, stLines, createdBy []StackTraceItem) {
+		l.debugStacks(err, stLines, createdBy)
+	})
+
+	if g != nil {
+		g.Go(run)
+		return
+	}
+	go run()
+}
+
+//callers returns the program counters of the stack of the caller skip frames above the caller of callers, to be
+//symbolized by stackFromPCs only if needed
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxCreatedByDepth)
+	return pcs[:runtime.Callers(skip+2, pcs)]
+}
+
+//guard returns a func running fn, which calls fail if fn fails: with its error, the location of the return statement
+//of fn which returned it (see returnStack) and the stack trace of pcs (where the goroutine was started), or if fn
+//panics, with a *PanicError, the stack trace of the panic and the stack trace of pcs
+func guard(pcs []uintptr, fn func() error, fail func(err error, stLines, createdBy []StackTraceItem)) func() error {
+	return func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r}
+				fail(err, goroutineStack(parseStackTrace(0)), stackFromPCs(pcs))
+			}
+		}()
+
+		if err = fn(); err != nil {
+			if stLines := returnStack(fn, err); stLines != nil {
+				fail(err, stLines, stackFromPCs(pcs))
+			} else {
+				fail(err, stackFromPCs(pcs), nil)
+			}
+		}
+		return err
+	}
+}
+
+//returnStack returns a frame of fn located at the return statement which returned err, or at the declaration of fn if
+//it cannot be told which one did (see findReturnLine). It returns nil if the source of fn is unknown (eg: method values).
+func returnStack(fn func() error, err error) []StackTraceItem {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return nil
+	}
+	file, line := f.FileLine(f.Entry())
+	line, ok := findReturnLine(file, line, err)
+	if !ok {
+		return nil
+	}
+
+	return []StackTraceItem{{
+		CallingObject: f.Name(),
+		SourcePathRef: file,
+		SourceLineRef: line,
+		MysteryNumber: -25,
+		PCOffset:      -1,
+		Entry:         f.Entry(),
+	}}
+}
+
+//findReturnLine finds the function declared at line of file, and returns the line of its return statement which
+//returned err: the only one not returning nil, or else the only one whose string literals are in the message of err
+//(eg: return fmt.Errorf("cannot sync %s: %w", id, err)). If there are several, it returns the line of the function.
+//ok is false if the function is not found.
+func findReturnLine(file string, line int, err error) (returnLine int, ok bool) {
+	f := parseSourceFile(file)
+	if f == nil {
+		return 0, false
+	}
+
+	var body *ast.BlockStmt //of the innermost function declared at line
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil || parsedFset.Position(n.Pos()).Line > line || parsedFset.Position(n.End()).Line < line {
+			return false
+		}
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			body = fn.Body
+		case *ast.FuncLit:
+			body = fn.Body
+		}
+		return true
+	})
+	if body == nil {
+		return 0, false
+	}
+
+	var returns, matching []*ast.ReturnStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.FuncLit:
+			return false //its returns are not the ones of fn
+		case *ast.ReturnStmt:
+			if len(stmt.Results) == 1 {
+				if ident, isIdent := stmt.Results[0].(*ast.Ident); isIdent && ident.Name == "nil" {
+					return false
+				}
+			}
+			returns = append(returns, stmt)
+			if returnMatches(stmt, err.Error()) {
+				matching = append(matching, stmt)
+			}
+		}
+		return true
+	})
+
+	switch {
+	case len(returns) == 1:
+		return parsedFset.Position(returns[0].Pos()).Line, true
+	case len(matching) == 1:
+		return parsedFset.Position(matching[0].Pos()).Line, true
+	}
+	return line, true
+}
+
+//returnMatches tells whether stmt has string literals, which are all in message (up to their first formatting verb)
+func returnMatches(stmt *ast.ReturnStmt, message string) bool {
+	found := false
+	matches := true
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		lit, isLit := n.(*ast.BasicLit)
+		if !isLit || lit.Kind != token.STRING {
+			return true
+		}
+		text, unquoteErr := strconv.Unquote(lit.Value)
+		if unquoteErr != nil {
+			return true
+		}
+		text, _, _ = strings.Cut(text, "%")
+		found = true
+		matches = matches && strings.Contains(message, text)
+		return true
+	})
+	return found && matches
+}
+
+//goroutineStack returns the frames of stLines, captured while recovering a panic in a func returned by guard,
+//which are between the panic and guard
+func goroutineStack(stLines []StackTraceItem) []StackTraceItem {
+	for i := range stLines {
+		if stLines[i].CallingObject == "panic" {
+			stLines = stLines[i+1:]
+			break
+		}
+	}
+	for len(stLines) > 1 && strings.HasPrefix(stLines[0].CallingObject, "runtime.") { //eg: runtime.panicmem, runtime.sigpanic
+		stLines = stLines[1:]
+	}
+
+	for i := range stLines {
+		if strings.HasPrefix(stLines[i].CallingObject, packagePath+".guard.") {
+			return stLines[:i]
+		}
+	}
+	return stLines
+}
+
+//stackFromPCs returns the stack trace of pcs, as returned by runtime.Callers, without the frames of the runtime starting
+//goroutines. Args are unknown.
+func stackFromPCs(pcs []uintptr) []StackTraceItem {
+	var stLines []StackTraceItem
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "runtime.main" && frame.Function != "runtime.goexit" {
+			item := StackTraceItem{
+				CallingObject: frame.Function,
+				SourcePathRef: frame.File,
+				SourceLineRef: frame.Line,
+				MysteryNumber: -25,
+				PCOffset:      -1,
+				PC:            frame.PC,
+				Entry:         frame.Entry,
+				Inlined:       frame.Func == nil, // the runtime has no Func for inlined frames
+			}
+			if !item.Inlined {
+				item.PCOffset = int64(frame.PC - frame.Entry)
+				item.MysteryNumber = item.PCOffset
+			}
+			stLines = append(stLines, item)
+		}
+		if !more {
+			return stLines
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package errlog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//chanSink sends reports to a channel
+type chanSink chan *Report
+
+func (s chanSink) Send(r *Report) error {
+	s <- r
+	return nil
+}
+
+//syncGroup runs functions right away, keeping their errors
+type syncGroup struct {
+	errs []error
+}
+
+func (g *syncGroup) Go(f func() error) {
+	g.errs = append(g.errs, f())
+}
+
+func TestGoPanic(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		printed strings.Builder
+	)
+	sink := make(chanSink, 1)
+	l := NewLogger(&Config{
+		PrintFunc: func(format string, data ...interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Fprintf(&printed, format+"\n", data...)
+		},
+		PrintError:       true,
+		PrintStackSource: true,
+		Sink:             sink,
+	}).(*logger)
+
+	var values []int
+	l.Go(func() error {
+		_ = values[3]
+		return nil
+	})
+	r := <-sink
+
+	var panicErr *PanicError
+	if !errors.As(r.Err, &panicErr) || !strings.Contains(r.Message, "index out of range") {
+		t.Fatalf("reported %T %q, want a *PanicError", r.Err, r.Message)
+	}
+	if r.Stack[0].CallingObject != packagePath+".TestGoPanic.func2" || len(r.Stack) != 1 {
+		t.Errorf("goroutine stack = %+v, want the func given to Go only", r.Stack)
+	}
+	if r.CreatedBy[0].CallingObject != packagePath+".TestGoPanic" {
+		t.Errorf("created by %s, want TestGoPanic", r.CreatedBy[0].CallingObject)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, want := range []string{"Goroutine created by:", "_ = values[3]", "l.Go(func() error {"} {
+		if !strings.Contains(printed.String(), want) {
+			t.Errorf("printed report does not contain %q:\n%s", want, printed.String())
+		}
+	}
+}
+
+func TestGoErrGroup(t *testing.T) {
+	sink := make(chanSink, 2)
+	l := NewLogger(&Config{PrintFunc: func(format string, data ...interface{}) {}, Sink: sink}).(*logger)
+
+	var g syncGroup
+	errFailed := errors.New("failed")
+	l.GoErrGroup(&g, func() error { return errFailed })
+	l.GoErrGroup(&g, func() error { panic("boom") })
+
+	if len(g.errs) != 2 || g.errs[0] != errFailed || g.errs[1].Error() != "panic: boom" {
+		t.Fatalf("group got errors %v", g.errs)
+	}
+	if r := <-sink; r.Err != errFailed || r.Stack[0].CallingObject != packagePath+".TestGoErrGroup.func2" || len(r.CreatedBy) == 0 {
+		t.Errorf("error reported from %s, want the func given to GoErrGroup with its creator", r.Stack[0].CallingObject)
+	}
+	if r := <-sink; len(r.CreatedBy) == 0 {
+		t.Error("panic reported without the stack trace of its creator")
+	}
+}
+
+func TestGoReturnLine(t *testing.T) {
+	sink := make(chanSink, 1)
+	l := NewLogger(&Config{PrintFunc: func(format string, data ...interface{}) {}, Sink: sink}).(*logger)
+
+	attempts := 3
+	l.Go(func() error {
+		if attempts == 0 {
+			return errors.New("not attempted")
+		}
+		return fmt.Errorf("timeout after %d attempts", attempts)
+	})
+
+	r := <-sink
+	b, err := os.ReadFile(r.Stack[0].SourcePathRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if line := lines[r.Stack[0].SourceLineRef-1]; !strings.Contains(line, `return fmt.Errorf("timeout`) {
+		t.Errorf("error located at line %d: %q, want the return of the timeout", r.Stack[0].SourceLineRef, line)
+	}
+	if len(r.CreatedBy) == 0 || r.CreatedBy[0].CallingObject != packagePath+".TestGoReturnLine" {
+		t.Errorf("error reported without the stack trace of its creator")
+	}
+}
+
+func TestGoCreatedByConfig(t *testing.T) {
+	var printed strings.Builder
+	l := NewLogger(&Config{
+		PrintFunc: func(format string, data ...interface{}) {
+			fmt.Fprintf(&printed, format+"\n", data...)
+		},
+		PrintError: true,
+	}).(*logger)
+
+	var g syncGroup
+	l.GoErrGroup(&g, func() error { return errors.New("failed") })
+
+	if !strings.Contains(printed.String(), "Goroutine created by:\n") || !strings.Contains(printed.String(), ".TestGoCreatedByConfig") {
+		t.Errorf("printed report does not show where the goroutine was created:\n%s", printed.String())
+	}
+	if strings.Contains(printed.String(), "Stack trace:") || strings.Contains(printed.String(), "l.GoErrGroup(&g") {
+		t.Errorf("printed report has stack traces with source, which are disabled:\n%s", printed.String())
+	}
+}
+
+func TestGoCreatedBySource(t *testing.T) {
+	var printed strings.Builder
+	l := NewLogger(&Config{
+		PrintFunc: func(format string, data ...interface{}) {
+			fmt.Fprintf(&printed, format+"\n", data...)
+		},
+		PrintSource: true,
+		LinesBefore: 1,
+		LinesAfter:  1,
+	}).(*logger)
+
+	var g syncGroup
+	l.GoErrGroup(&g, func() error { return errors.New("failed") })
+
+	_, createdBy, found := strings.Cut(printed.String(), "Goroutine created by:\n")
+	if !found || !strings.Contains(createdBy, "l.GoErrGroup(&g") {
+		t.Errorf("printed report does not show the source of the goroutine creation:\n%s", printed.String())
+	}
+	if strings.Contains(printed.String(), "failing line not found") {
+		t.Errorf("printed report does not show the return statement as failing line:\n%s", printed.String())
+	}
+}
@@ -0,0 +1,201 @@
+package errlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+//NewHandler returns an http.Handler listing the reports collected by agg: groups of reports with their
+//counts, and the most recent reports, each with its source excerpt and stack trace.
+//The page is rendered as HTML, add ?format=json to the URL to get JSON instead.
+//
+//Like net/http/pprof, it is meant to be mounted on a debug endpoint of a running service:
+//
+//	agg := errlog.NewAggregator()
+//	errlog.DefaultLogger.Config().Aggregator = agg
+//	http.Handle("/debug/errlog", errlog.NewHandler(agg))
+func NewHandler(agg *Aggregator) http.Handler {
+	cfg := DefaultLogger.Config()
+	return &handler{
+		aggregator:  agg,
+		linesBefore: cfg.LinesBefore,
+		linesAfter:  cfg.LinesAfter,
+	}
+}
+
+//handler serves the reports of an Aggregator
+type handler struct {
+	aggregator  *Aggregator
+	linesBefore int //How many lines to render *before* the Debug call in excerpts
+	linesAfter  int //How many lines to render *after* the Debug call in excerpts
+}
+
+//handlerPage is the content served by handler
+type handlerPage struct {
+	Groups []groupView  `json:"groups"`
+	Recent []reportView `json:"recent"`
+}
+
+type groupView struct {
+	ID        string     `json:"id"`
+	Count     int        `json:"count"`
+	FirstSeen time.Time  `json:"first_seen"`
+	LastSeen  time.Time  `json:"last_seen"`
+	Sample    reportView `json:"sample"`
+}
+
+type reportView struct {
+	Time        time.Time   `json:"time"`
+	ErrorType   string      `json:"error_type"`
+	Message     string      `json:"message"`
+	Fingerprint string      `json:"fingerprint"`
+	Stack       []frameView `json:"stack"`
+	Source      *sourceView `json:"source,omitempty"`
+}
+
+type frameView struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+type sourceView struct {
+	File        string     `json:"file"`
+	FailingLine int        `json:"failing_line,omitempty"` //Line number, 0 if not found
+	Lines       []lineView `json:"lines"`
+}
+
+type lineView struct {
+	Number  int    `json:"number,omitempty"`
+	Text    string `json:"text"`
+	Failing bool   `json:"failing,omitempty"`
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	page := h.page()
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(page); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := handlerTemplate.Execute(w, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+//page builds the views of the aggregator content
+func (h *handler) page() handlerPage {
+	sources := make(map[string]*sourceView) //excerpts already loaded, by file:line
+
+	page := handlerPage{
+		Groups: []groupView{},
+		Recent: []reportView{},
+	}
+	for _, g := range h.aggregator.Snapshot() {
+		page.Groups = append(page.Groups, groupView{
+			ID:        g.ID,
+			Count:     g.Count,
+			FirstSeen: g.FirstSeen,
+			LastSeen:  g.LastSeen,
+			Sample:    h.reportView(g.Sample, sources),
+		})
+	}
+	for _, r := range h.aggregator.Recent() {
+		page.Recent = append(page.Recent, h.reportView(r, sources))
+	}
+
+	return page
+}
+
+func (h *handler) reportView(r *Report, sources map[string]*sourceView) reportView {
+	v := reportView{
+		Time:        r.Time,
+		ErrorType:   r.ErrorType,
+		Message:     r.Message,
+		Fingerprint: r.Fingerprint,
+		Stack:       make([]frameView, len(r.Stack)),
+	}
+	for i, item := range r.Stack {
+		v.Stack[i] = frameView{Function: item.CallingObject, File: item.SourcePathRef, Line: item.SourceLineRef}
+	}
+	if len(r.Stack) == 0 {
+		return v
+	}
+
+	key := fmt.Sprintf("%s:%d", r.Stack[0].SourcePathRef, r.Stack[0].SourceLineRef)
+	if _, ok := sources[key]; !ok {
+		sources[key] = h.sourceView(r.Stack[0].SourcePathRef, r.Stack[0].SourceLineRef)
+	}
+	v.Source = sources[key]
+
+	return v
+}
+
+//sourceView loads the excerpt around line of file, nil if the file cannot be read
+func (h *handler) sourceView(file string, line int) *sourceView {
+	ex, err := loadSourceExcerpt(file, line, h.linesBefore, h.linesAfter)
+	if err != nil {
+		return nil
+	}
+
+	v := &sourceView{File: shortSourcePath(file)}
+	if ex.FailingLine != -1 {
+		v.FailingLine = ex.FailingLine + 1
+	}
+	for _, l := range ex.Excerpt() {
+		v.Lines = append(v.Lines, lineView{Number: l.Number, Text: l.Text, Failing: l.Failing})
+	}
+
+	return v
+}
+
+var handlerTemplate = template.Must(template.New("errlog").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>/debug/errlog</title>
+<style>
+.failing { color: #c00; font-weight: bold; }
+.meta { color: #666; }
+pre { background: #f4f4f4; padding: 0.5em; }
+</style>
+</head>
+<body>
+<h1>/debug/errlog</h1>
+<p><a href="?format=json">JSON</a></p>
+
+<h2>Groups</h2>
+{{if not .Groups}}<p>No report yet.</p>{{end}}
+<table>
+<tr><th>Count</th><th>Group</th><th>Error</th><th>First seen</th><th>Last seen</th></tr>
+{{range .Groups}}<tr><td>{{.Count}}</td><td><a href="#{{.ID}}">{{.ID}}</a></td><td>{{.Sample.Message}}</td><td>{{.FirstSeen.Format "2006-01-02 15:04:05"}}</td><td>{{.LastSeen.Format "2006-01-02 15:04:05"}}</td></tr>
+{{end}}</table>
+
+{{range .Groups}}<h3 id="{{.ID}}">{{.ID}} ({{.Count}} times)</h3>
+{{template "report" .Sample}}
+{{end}}
+
+<h2>Recent</h2>
+{{if not .Recent}}<p>No report yet.</p>{{end}}
+{{range .Recent}}{{template "report" .}}{{end}}
+</body>
+</html>
+
+{{define "report"}}<div>
+<p><b>{{.ErrorType}}</b>: {{.Message}} <span class="meta">at {{.Time.Format "2006-01-02 15:04:05.000"}}, group <a href="#{{.Fingerprint}}">{{.Fingerprint}}</a></span></p>
+{{with .Source}}<pre>{{.File}}
+{{range .Lines}}<span{{if .Failing}} class="failing"{{end}}>{{if .Number}}{{.Number}}: {{end}}{{.Text}}</span>
+{{end}}</pre>{{end}}
+<pre>{{range .Stack}}{{.Function}} ({{.File}}:{{.Line}})
+{{end}}</pre>
+</div>
+{{end}}`))
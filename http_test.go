@@ -0,0 +1,96 @@
+package errlog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestHandler(t *testing.T) {
+	defer func(osFs afero.Fs) { fs = osFs }(fs)
+	fs = afero.NewMemMapFs()
+	source := "package main\n\nfunc main() {\n\terr := load()\n\terrlog.Debug(err)\n}\n"
+	if err := afero.WriteFile(fs, "/src/main.go", []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agg := NewAggregator()
+	stack := []StackTraceItem{{CallingObject: "main.main", SourcePathRef: "/src/main.go", SourceLineRef: 5}}
+	for i := 0; i < 2; i++ {
+		agg.Add(newReport(errors.New("cannot load <config>"), stack, nil))
+	}
+	h := NewHandler(agg)
+
+	cases := []struct {
+		name        string
+		url         string
+		contentType string
+		check       func(t *testing.T, body string)
+	}{
+		{
+			name:        "html",
+			url:         "/debug/errlog",
+			contentType: "text/html; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				for _, want := range []string{
+					"<td>2</td>",
+					"cannot load &lt;config&gt;",
+					`<span class="failing">4: 	err := load()</span>`,
+					"main.main (/src/main.go:5)",
+				} {
+					if !strings.Contains(body, want) {
+						t.Errorf("page does not contain %q:\n%s", want, body)
+					}
+				}
+				if strings.Contains(body, "<config>") {
+					t.Error("page contains the unescaped error message")
+				}
+			},
+		},
+		{
+			name:        "json",
+			url:         "/debug/errlog?format=json",
+			contentType: "application/json; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				var page handlerPage
+				if err := json.Unmarshal([]byte(body), &page); err != nil {
+					t.Fatal(err)
+				}
+				if len(page.Groups) != 1 || page.Groups[0].Count != 2 || len(page.Recent) != 2 {
+					t.Fatalf("page = %+v, want 1 group of 2 reports", page)
+				}
+				sample := page.Groups[0].Sample
+				if sample.Message != "cannot load <config>" || sample.ErrorType != "*errors.errorString" || sample.Stack[0].Line != 5 {
+					t.Errorf("sample = %+v", sample)
+				}
+				if sample.Source == nil || sample.Source.FailingLine != 4 || len(sample.Source.Lines) == 0 {
+					t.Errorf("sample source = %+v, want the excerpt with failing line 4", sample.Source)
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.url, nil))
+			if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != c.contentType {
+				t.Fatalf("status %d, Content-Type %q, want 200 and %q", rec.Code, rec.Header().Get("Content-Type"), c.contentType)
+			}
+			c.check(t, rec.Body.String())
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewHandler(NewAggregator()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errlog?format=json", nil))
+		if body := strings.Join(strings.Fields(rec.Body.String()), ""); body != `{"groups":[],"recent":[]}` {
+			t.Errorf("empty page = %s", rec.Body.String())
+		}
+	})
+}
@@ -0,0 +1,236 @@
+package errlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//configSettings are the settings of Config which can be loaded from files (by key) and from the environment
+//(ERRLOG_ followed by the key in upper case, eg: ERRLOG_LINES_BEFORE)
+var configSettings = map[string]func(cfg *Config, value string) error{
+	"mode": func(cfg *Config, value string) error {
+		switch strings.ToLower(value) {
+		case "enabled":
+			cfg.Mode = ModeEnabled
+		case "disabled":
+			cfg.Mode = ModeDisabled
+		default:
+			return fmt.Errorf("unknown mode %q, want enabled or disabled", value)
+		}
+		return nil
+	},
+	"level": func(cfg *Config, value string) error {
+		return cfg.Level.UnmarshalText([]byte(value))
+	},
+	"lines_before":              intSetting(func(cfg *Config) *int { return &cfg.LinesBefore }),
+	"lines_after":               intSetting(func(cfg *Config) *int { return &cfg.LinesAfter }),
+	"print_stack":               boolSetting(func(cfg *Config) *bool { return &cfg.PrintStack }),
+	"print_source":              boolSetting(func(cfg *Config) *bool { return &cfg.PrintSource }),
+	"print_error":               boolSetting(func(cfg *Config) *bool { return &cfg.PrintError }),
+	"exit_on_debug_success":     boolSetting(func(cfg *Config) *bool { return &cfg.ExitOnDebugSuccess }),
+	"disable_stack_indentation": boolSetting(func(cfg *Config) *bool { return &cfg.DisableStackIndentation }),
+	"print_stack_source":        boolSetting(func(cfg *Config) *bool { return &cfg.PrintStackSource }),
+	"stack_source_depth":        intSetting(func(cfg *Config) *int { return &cfg.StackSourceDepth }),
+	"stack_source_lines":        intSetting(func(cfg *Config) *int { return &cfg.StackSourceLines }),
+	"vars_max_depth":            intSetting(func(cfg *Config) *int { return &cfg.VarsMaxDepth }),
+	"vars_max_items":            intSetting(func(cfg *Config) *int { return &cfg.VarsMaxItems }),
+	"decode_stack_args":         boolSetting(func(cfg *Config) *bool { return &cfg.DecodeStackArgs }),
+	"enable": func(cfg *Config, value string) (err error) {
+		cfg.Enable = nil
+		if value != "" {
+			cfg.Enable, err = ParseEnableRules(value)
+		}
+		return err
+	},
+}
+
+func intSetting(field func(cfg *Config) *int) func(cfg *Config, value string) error {
+	return func(cfg *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		*field(cfg) = n
+		return nil
+	}
+}
+
+func boolSetting(field func(cfg *Config) *bool) func(cfg *Config, value string) error {
+	return func(cfg *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		*field(cfg) = b
+		return nil
+	}
+}
+
+//LoadConfig returns a copy of base updated with the settings of the YAML or JSON file at path (skipped if path is
+//empty), then with the ERRLOG_* environment variables, eg:
+//
+//	# errlog.yaml
+//	mode: enabled
+//	lines_before: 6
+//	print_stack: true
+//
+//	ERRLOG_MODE=disabled ./app
+//
+//Keys are the names of the Config fields in snake_case (mode, level, lines_before, lines_after, print_stack,
+//print_source, print_error, exit_on_debug_success, disable_stack_indentation, print_stack_source,
+//stack_source_depth, stack_source_lines, vars_max_depth, vars_max_items, decode_stack_args, enable).
+//enable is given as parsed by ParseEnableRules, eg: ERRLOG_ENABLE=github.com/acme/billing/...,-github.com/acme/billing/legacy
+//The config is then checked by Validate: errors are returned, warnings are left to the caller (see Config.AutoFix).
+//If base is nil, DefaultLogger's config is used.
+func LoadConfig(base *Config, path string) (*Config, error) {
+	if base == nil {
+		base = DefaultLogger.Config()
+	}
+	cfg := *base
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyConfigFile(&cfg, content); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	if err := applyConfigEnv(&cfg, os.Environ()); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, d := range cfg.Validate() {
+		if d.Severity == SeverityError {
+			errs = append(errs, errors.New(d.String()))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return &cfg, nil
+}
+
+//applyConfigFile applies the settings of a YAML or JSON file to cfg
+func applyConfigFile(cfg *Config, content []byte) error {
+	var settings map[string]interface{}
+	if err := yaml.Unmarshal(content, &settings); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		set, ok := configSettings[key]
+		if !ok {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+		switch settings[key].(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return fmt.Errorf("%s: want a single value", key)
+		}
+		if err := set(cfg, fmt.Sprint(settings[key])); err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+	}
+	return nil
+}
+
+//applyConfigEnv applies the ERRLOG_* variables of environ (as returned by os.Environ) to cfg
+func applyConfigEnv(cfg *Config, environ []string) error {
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		if !strings.HasPrefix(name, "ERRLOG_") {
+			continue
+		}
+		set, ok := configSettings[strings.ToLower(strings.TrimPrefix(name, "ERRLOG_"))]
+		if !ok {
+			continue // might be read by something else
+		}
+		if err := set(cfg, value); err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+//Watch checks the file at path every interval, and when its modification time or size changes, loads it like
+//LoadConfig does on top of base and swaps the config of l with it (see Logger.SetConfig), until ctx is done. If base
+//is nil, the config of l when Watch is called is used. If the file cannot be loaded, the config is kept and the error
+//is given to onError (once until it changes), which may be nil. Run it in its own goroutine:
+//
+//	go errlog.Watch(ctx, errlog.DefaultLogger, base, "errlog.yaml", 5*time.Second, nil)
+func Watch(ctx context.Context, l Logger, base *Config, path string, interval time.Duration, onError func(error)) error {
+	if base == nil { //a snapshot, so that reloads do not stack on each other
+		cfg := *l.Config()
+		base = &cfg
+	}
+
+	var (
+		last    fileVersion //the file is expected to be loaded already
+		lastErr string
+	)
+	if info, err := os.Stat(path); err == nil {
+		last = fileVersion{info.ModTime(), info.Size()}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		var cfg *Config
+		info, err := os.Stat(path)
+		if err == nil {
+			version := fileVersion{info.ModTime(), info.Size()}
+			if version.equal(last) {
+				continue
+			}
+			last = version
+			cfg, err = LoadConfig(base, path)
+		} else {
+			last = fileVersion{} //reload the file when it is back
+		}
+		if err != nil {
+			if onError != nil && err.Error() != lastErr {
+				onError(err)
+			}
+			lastErr = err.Error()
+			continue
+		}
+
+		lastErr = ""
+		l.SetConfig(cfg)
+	}
+}
+
+//fileVersion tells a version of a file from another
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+//equal tells whether v and w are the same version of the file
+func (v fileVersion) equal(w fileVersion) bool {
+	return v.modTime.Equal(w.modTime) && v.size == w.size
+}
@@ -0,0 +1,98 @@
+package errlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errlog.yaml")
+	if err := os.WriteFile(path, []byte("mode: disabled\nlines_before: 6\nprint_stack: true\nlevel: warn\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ERRLOG_MODE", "enabled")
+
+	cfg, err := LoadConfig(&Config{PrintSource: true}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Mode != ModeEnabled || cfg.LinesBefore != 6 || !cfg.PrintStack || !cfg.PrintSource || cfg.Level != LevelWarn {
+		t.Errorf("LoadConfig() = %+v", cfg)
+	}
+
+	t.Setenv("ERRLOG_ENABLE", "github.com/acme/...,-github.com/acme/legacy")
+	if cfg, err := LoadConfig(nil, path); err != nil || cfg.Enable.String() != "github.com/acme/...,-github.com/acme/legacy" {
+		t.Errorf("LoadConfig() did not set Enable from ERRLOG_ENABLE (error: %v)", err)
+	}
+
+	t.Setenv("ERRLOG_LINES_AFTER", "-3")
+	if _, err := LoadConfig(nil, path); err == nil {
+		t.Error("LoadConfig() accepted a negative LinesAfter")
+	}
+
+	if err := os.WriteFile(path, []byte("lines_befor: 6\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(nil, path); err == nil {
+		t.Error("LoadConfig() accepted an unknown setting")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errlog.json")
+	if err := os.WriteFile(path, []byte(`{"lines_before": 4}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{PrintFunc: func(format string, data ...interface{}) {}, LinesBefore: 2}
+	l := NewLogger(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go Watch(ctx, l, nil, path, time.Millisecond, func(err error) { errs <- err })
+
+	time.Sleep(20 * time.Millisecond)
+	if l.Config() != cfg {
+		t.Fatal("Watch reloaded the file while it did not change")
+	}
+
+	//write versions of the file until Watch, which may not have looked at the file yet, sees one
+	write := func(version int, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		at := time.Now().Add(time.Duration(version) * time.Hour)
+		if err := os.Chtimes(path, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for version, deadline := 1, time.Now().Add(5*time.Second); ; version++ {
+		write(version, `{"lines_before": "x"}`)
+		select {
+		case <-errs:
+		case <-time.After(10 * time.Millisecond):
+			if time.Now().After(deadline) {
+				t.Fatal("Watch did not report the invalid file")
+			}
+			continue
+		}
+		break
+	}
+
+	waitLinesBefore := func(want int) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); l.Config().LinesBefore != want; time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Watch did not reload the file: LinesBefore = %d, want %d", l.Config().LinesBefore, want)
+			}
+		}
+	}
+	write(100, `{"lines_before": 8}`)
+	waitLinesBefore(8)
+	write(101, `{"lines_after": 1}`) // loaded on top of the first config, not of the previous reload
+	waitLinesBefore(2)
+}
@@ -1,12 +1,15 @@
 package errlog
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
-	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/fatih/color"
-	"github.com/sirupsen/logrus"
-	"github.com/spf13/afero"
 )
 
 var (
@@ -33,6 +36,57 @@ type Logger interface {
 	Disable(bool)
 }
 
+//The loggers of NewLogger and NewFanOutLogger also implement the following interfaces, and io.Closer to deliver queued
+//reports, flush pending summaries of repeated reports and stop background work. Other implementations of Logger may
+//not: check them with a type assertion, eg: l.(errlog.VarsDebugger).DebugVars(err, "userID", userID)
+var _ interface {
+	Logger
+	VarsDebugger
+	ContextDebugger
+	StackDebugger
+	Flusher
+	GoRunner
+	io.Closer
+} = (*logger)(nil)
+
+//VarsDebugger is a Logger which can report named values along with an error
+type VarsDebugger interface {
+	//DebugVars is like Debug, but also prints named values given as alternated names and values (eg: "userID", userID)
+	//Struct fields tagged `errlog:"secret"` are masked
+	DebugVars(err error, keyvals ...interface{}) bool
+	//DebugMap is like DebugVars, with named values given as a map
+	DebugMap(err error, vars map[string]interface{}) bool
+}
+
+//ContextDebugger is a Logger which can record reports on the span of a context
+type ContextDebugger interface {
+	//DebugContext is like Debug, giving ctx to Config.Exporter so that the report is recorded on the span of ctx
+	DebugContext(ctx context.Context, err error) bool
+}
+
+//StackDebugger is a Logger which can report an error with a stack trace of another place
+type StackDebugger interface {
+	//DebugStack is like Debug, but reports err with the given stack trace instead of the one of the caller (see Stack)
+	DebugStack(err error, stack []StackTraceItem) bool
+}
+
+//Flusher is a Logger which may deliver reports in the background (see Config.Async)
+type Flusher interface {
+	//Flush waits until the reports queued in async mode are delivered, or until ctx is done
+	Flush(ctx context.Context) error
+	//Stats returns how many reports were delivered and dropped
+	Stats() Stats
+}
+
+//GoRunner is a Logger which can start goroutines reporting their failures
+type GoRunner interface {
+	//Go runs fn in a new goroutine, reporting the error it returns and recovering its panics, which are reported with
+	//the stack trace of the code which called Go
+	Go(fn func() error)
+	//GoErrGroup is like Go, with fn run by g (eg: an errgroup.Group) which gets its error, or a *PanicError if it panicked
+	GoErrGroup(g ErrGroup, fn func() error)
+}
+
 //Config holds the configuration for a logger
 type Config struct {
 	PrintFunc               func(format string, data ...interface{}) //Printer func (eg: fmt.Printf)
@@ -44,6 +98,20 @@ type Config struct {
 	ExitOnDebugSuccess      bool                                     //Shall we os.Exit(1) after Debug has finished logging everything ? (doesn't happen when err is nil)
 	DisableStackIndentation bool                                     //Shall we print stack vertically instead of indented
 	Mode                    int
+	RateLimit               *RateLimitConfig //Shall we deduplicate repeated reports ? nil disables it (see RateLimitConfig)
+	Aggregator              *Aggregator      //Shall we group reports by fingerprint ? nil disables it (see NewAggregator)
+	StackFilter             *StackFilter     //Shall we hide or collapse some frames when printing stack trace ? nil prints every frame (see StackFilter)
+	PrintStackSource        bool             //Shall we print stack trace with source code around the call site of each user frame ? yes/no
+	StackSourceDepth        int              //How many user frames, from the top of the stack, get their source printed with PrintStackSource (0 means all)
+	StackSourceLines        int              //How many lines to print before and after each call site with PrintStackSource (0 means 2)
+	VarsMaxDepth            int              //How deep nested values given to DebugVars are printed (0 means 3)
+	VarsMaxItems            int              //How many items of slices and maps given to DebugVars are printed (0 means 10)
+	DecodeStackArgs         bool             //Shall we decode the args of stack trace items using the signatures found in source code ? yes/no
+	Exporter                Exporter         //Shall we export reports as span events ? nil disables it (see Exporter)
+	Sink                    Sink             //Shall we send reports to a sink (eg: SentrySink, FanOut) ? nil disables it
+	Level                   Level            //Level of the reports of this logger, for filtering by sinks (0 means LevelError)
+	Async                   *AsyncConfig     //Shall we deliver reports on background workers ? nil delivers them on the goroutine of the caller (see AsyncConfig)
+	Enable                  *EnableRules     //Shall we only report errors debugged from some packages or files ? nil reports them all (see EnableRules)
 }
 
 // PrintSourceOptions represents config for (*logger).PrintSource func
@@ -56,111 +124,278 @@ type PrintSourceOptions struct {
 
 //logger holds logger object, implementing Logger interface
 type logger struct {
-	config             *Config //config for the logger
-	stackDepthOverload int     //stack depth to ignore when reading stack
+	config    atomic.Pointer[Config] //config for the logger, swapped atomically by SetConfig
+	sinksOnly bool                   //whether reports are only sent to Config.Sink, not printed (see NewFanOutLogger)
+	limiter   *rateLimiter           //deduplicates repeated reports, nil if Config.RateLimit is nil
+	queue     *asyncQueue            //delivers reports in async mode, nil until the first report if Config.Async is set
+	mu        sync.Mutex             //guards limiter and queue
+	printMu   sync.Mutex             //prevents concurrent reports from being interleaved
+	delivered uint64                 //atomic count of delivered reports
+	dropped   uint64                 //atomic count of reports dropped by closed queues
+
+	stackDepthOverload int32 //atomic stack depth to ignore when reading the next stack (see Overload)
 }
 
 //NewLogger creates a new logger struct with given config
 func NewLogger(cfg *Config) Logger {
-	l := logger{
-		config:             cfg,
-		stackDepthOverload: 0,
-	}
-
-	l.Doctor()
+	diagnose(cfg)
+	return newLogger(cfg)
+}
 
-	return &l
+//newLogger creates a logger with cfg, without checking it
+func newLogger(cfg *Config) *logger {
+	l := &logger{}
+	l.config.Store(cfg)
+	return l
 }
 
 // Debug wraps up Logger debugging funcs related to an error
 // If the given error is nil, it returns immediately
 // It relies on Logger.Config to determine what will be printed or executed
 func (l *logger) Debug(uErr error) bool {
-	if l.config.Mode == ModeDisabled {
+	return l.debugDepth(1, context.Background(), uErr, nil)
+}
+
+//DebugVars is like Debug, but also prints the given named values, as alternated names and values:
+//
+//	l.DebugVars(err, "userID", userID, "req", req)
+func (l *logger) DebugVars(uErr error, keyvals ...interface{}) bool {
+	return l.debugDepth(1, context.Background(), uErr, keyvals)
+}
+
+//DebugMap is like DebugVars, with the named values given as a map
+func (l *logger) DebugMap(uErr error, vars map[string]interface{}) bool {
+	if uErr == nil {
+		return l.debugDepth(1, context.Background(), uErr, nil)
+	}
+	return l.debugDepth(1, context.Background(), uErr, mapToKeyvals(vars))
+}
+
+//DebugContext is like Debug, giving ctx to Config.Exporter so that the report is recorded on the span of ctx
+func (l *logger) DebugContext(ctx context.Context, uErr error) bool {
+	return l.debugDepth(1, ctx, uErr, nil)
+}
+
+//debugDepth is the implementation of Debug, DebugVars, DebugMap and DebugContext. skip is the number of frames between
+//the caller of debugDepth and the Debug call to report (eg: 1 for the Debug method called by the code of the user).
+func (l *logger) debugDepth(skip int, ctx context.Context, uErr error, keyvals []interface{}) bool {
+	if l.Config().Mode == ModeDisabled {
 		return uErr != nil
 	}
-	l.Doctor()
 	if uErr == nil {
 		return false
 	}
 
-	stLines := parseStackTrace(1 + l.stackDepthOverload)
+	skip += int(atomic.SwapInt32(&l.stackDepthOverload, 0))
+
+	if rules := l.Config().Enable; rules != nil && !rules.allowsCaller(1+skip) {
+		return true
+	}
+
+	stLines := parseStackTrace(1 + skip)
+
+	var vars []Var
+	if len(keyvals) > 0 {
+		vars = newVarsFormatter(l.Config()).formatKeyvals(keyvals)
+	}
+
+	return l.deliver(ctx, uErr, stLines, nil, vars)
+}
+
+//DebugStack is like Debug, but reports uErr with the given stack trace instead of the one of the caller.
+//The first item of stLines is considered as the Debug call (see Stack)
+func (l *logger) DebugStack(uErr error, stLines []StackTraceItem) bool {
+	return l.debugStacks(uErr, stLines, nil)
+}
+
+//debugStacks is the implementation of DebugStack, also reporting the stack trace of the code which started the
+//goroutine, if given (see Go)
+func (l *logger) debugStacks(uErr error, stLines, createdBy []StackTraceItem) bool {
+	if l.Config().Mode == ModeDisabled {
+		return uErr != nil
+	}
+	if uErr == nil {
+		return false
+	}
+
+	if rules := l.Config().Enable; rules != nil && len(stLines) > 0 && !rules.Allows(packageOf(stLines[0].CallingObject), stLines[0].SourcePathRef) {
+		return true
+	}
+
+	return l.deliver(context.Background(), uErr, stLines, createdBy, nil)
+}
+
+//deliver queues the report of uErr in async mode, or processes it right away with debugStack.
+//The time of the call is captured here, so that reports processed later by a worker keep it.
+func (l *logger) deliver(ctx context.Context, uErr error, stLines, createdBy []StackTraceItem, vars []Var) bool {
+	at := time.Now()
+	if l.Config().Async == nil || l.Config().ExitOnDebugSuccess { // exiting needs the report to be printed first
+		return l.debugStack(ctx, uErr, stLines, createdBy, vars, at)
+	}
+
+	l.mu.Lock()
+	if l.queue == nil {
+		l.queue = newAsyncQueue(*l.Config().Async, func(job asyncJob) {
+			if job.report != nil {
+				l.output(job.report)
+				return
+			}
+			l.debugStack(job.ctx, job.err, job.stLines, job.createdBy, job.vars, job.at)
+		})
+	}
+	q := l.queue
+	l.mu.Unlock()
+
+	job := asyncJob{ctx: ctx, err: uErr, stLines: stLines, createdBy: createdBy, vars: vars, at: at}
+	if l.Config().Exporter != nil && len(stLines) > 0 { //span events must be added before the span of ctx ends
+		report := l.newReport(uErr, stLines, createdBy, vars, at)
+		if !l.admit(report) {
+			return true
+		}
+		l.export(ctx, report)
+		job = asyncJob{report: report}
+	}
+
+	if !q.enqueue(job) { // closed meanwhile
+		if job.report != nil {
+			l.output(job.report)
+			return true
+		}
+		return l.debugStack(ctx, uErr, stLines, createdBy, vars, at)
+	}
+	return true
+}
+
+//debugStack logs uErr, debugged at the given time, with stLines as stack trace, createdBy as the stack trace of the
+//creator of the goroutine (nil if unknown) and vars, uErr must not be nil
+func (l *logger) debugStack(ctx context.Context, uErr error, stLines, createdBy []StackTraceItem, vars []Var, at time.Time) bool {
 	if stLines == nil || len(stLines) < 1 {
 		l.Printf("Error: %s", uErr)
 		l.Printf("Errlog tried to debug the error but the stack trace seems empty. If you think this is an error, please open an issue at https://github.com/snwfdhmp/errlog/issues/new and provide us logs to investigate.")
 		return true
 	}
 
-	if l.config.PrintError {
-		l.Printf("Error in %s: %s", stLines[0].CallingObject, color.YellowString(uErr.Error()))
+	report := l.newReport(uErr, stLines, createdBy, vars, at)
+	if l.admit(report) {
+		l.export(ctx, report)
+		l.output(report)
 	}
 
-	if l.config.PrintSource {
-		l.DebugSource(stLines[0].SourcePathRef, stLines[0].SourceLineRef)
+	if l.Config().ExitOnDebugSuccess {
+		os.Exit(1)
 	}
 
-	if l.config.PrintStack {
-		l.Printf("Stack trace:")
-		l.printStack(stLines)
+	return true
+}
+
+//newReport builds the report of uErr, debugged at the given time, as configured
+func (l *logger) newReport(uErr error, stLines, createdBy []StackTraceItem, vars []Var, at time.Time) *Report {
+	if l.Config().DecodeStackArgs {
+		stLines = append([]StackTraceItem(nil), stLines...) //stLines may be the one of the caller (eg: DebugStack)
+		for i := range stLines {
+			stLines[i].Params = decodeArgs(stLines[i])
+		}
 	}
 
-	if l.config.ExitOnDebugSuccess {
-		os.Exit(1)
+	report := newReport(uErr, stLines, vars)
+	report.Time = at
+	report.CreatedBy = createdBy
+	if l.Config().Level != 0 {
+		report.Level = l.Config().Level
 	}
+	return report
+}
 
-	l.stackDepthOverload = 0
+//admit counts r in Config.Aggregator, and tells whether it should be delivered according to Config.RateLimit
+func (l *logger) admit(r *Report) bool {
+	if l.Config().Aggregator != nil { //counts every report, including the ones suppressed by RateLimit
+		l.Config().Aggregator.Add(r)
+	}
+	return l.allowReport(r)
+}
 
-	return true
+//export records r with Config.Exporter, if any
+func (l *logger) export(ctx context.Context, r *Report) {
+	if l.Config().Exporter == nil {
+		return
+	}
+	if err := l.Config().Exporter.Export(ctx, NewEvent(r, l.Config().LinesBefore, l.Config().LinesAfter)); err != nil {
+		l.Printf("errlog: cannot export report: %s", err)
+	}
 }
 
-//DebugSource prints certain lines of source code of a file for debugging, using (*logger).config as configurations
-func (l *logger) DebugSource(filepath string, debugLineNumber int) {
-	filepathShort := filepath
-	if gopath != "" {
-		filepathShort = strings.Replace(filepath, gopath+"/src/", "", -1)
+//output sends r to Config.Sink and prints it
+func (l *logger) output(r *Report) {
+	if l.Config().Sink != nil {
+		if err := l.Config().Sink.Send(r); err != nil {
+			l.Printf("errlog: cannot send report: %s", err)
+		}
 	}
 
-	b, err := afero.ReadFile(fs, filepath)
-	if err != nil {
-		l.Printf("errlog: cannot read file '%s': %s. If sources are not reachable in this environment, you should set PrintSource=false in logger config.", filepath, err)
-		return
-		// l.Debug(err)
+	if !l.sinksOnly {
+		l.printMu.Lock()
+		l.printReport(r)
+		l.printMu.Unlock()
+	}
+	atomic.AddUint64(&l.delivered, 1)
+}
+
+//printReport prints r as configured, with Printf
+func (l *logger) printReport(r *Report) {
+	if l.Config().PrintError {
+		l.Printf("Error in %s: %s", r.Stack[0].CallingObject, color.YellowString(r.Message))
 	}
-	lines := strings.Split(string(b), "\n")
 
-	// set line range to print based on config values and debugLineNumber
-	minLine := debugLineNumber - l.config.LinesBefore
-	maxLine := debugLineNumber + l.config.LinesAfter
+	if l.Config().PrintSource {
+		l.DebugSource(r.Stack[0].SourcePathRef, r.Stack[0].SourceLineRef)
+	}
 
-	//delete blank lines from range and clean range if out of lines range
-	deleteBlankLinesFromRange(lines, &minLine, &maxLine)
+	if len(r.Vars) > 0 {
+		l.Printf("Variables:")
+		for _, v := range r.Vars {
+			l.Printf("  %s = %s", color.CyanString(v.Name), v.Value)
+		}
+	}
 
-	//free some memory from unused values
-	lines = lines[:maxLine+1]
+	//a failure in a goroutine started with Go has no other context than both stacks, so they get their sources too
+	withSource := l.Config().PrintStackSource || len(r.CreatedBy) > 0 && l.Config().PrintSource
+	switch {
+	case withSource:
+		l.Printf("Stack trace:")
+		l.printStackSource(r.Stack)
+	case l.Config().PrintStack:
+		l.Printf("Stack trace:")
+		l.printStack(r.Stack)
+	}
 
-	//find func line and adjust minLine if below
-	funcLine := findFuncLine(lines, debugLineNumber)
-	if funcLine > minLine {
-		minLine = funcLine + 1
+	if len(r.CreatedBy) > 0 {
+		l.Printf("Goroutine created by:")
+		if withSource {
+			l.printStackSource(r.CreatedBy)
+		} else {
+			l.printStack(r.CreatedBy)
+		}
 	}
+}
 
-	//try to find failing line if any
-	failingLineIndex, columnStart, columnEnd := findFailingLine(lines, funcLine, debugLineNumber)
+//DebugSource prints certain lines of source code of a file for debugging, using (*logger).config as configurations
+func (l *logger) DebugSource(filepath string, debugLineNumber int) {
+	filepathShort := shortSourcePath(filepath)
+
+	ex, err := loadSourceExcerpt(filepath, debugLineNumber, max(l.Config().LinesBefore, 0), max(l.Config().LinesAfter, 0))
+	if err != nil {
+		l.Printf("errlog: cannot read file '%s': %s. If sources are not reachable in this environment, you should set PrintSource=false in logger config.", filepath, err)
+		return
+		// l.Debug(err)
+	}
 
-	if failingLineIndex != -1 {
-		l.Printf("line %d of %s:%d", failingLineIndex+1, filepathShort, failingLineIndex+1)
+	if ex.FailingLine != -1 {
+		l.Printf("line %d of %s:%d", ex.FailingLine+1, filepathShort, ex.FailingLine+1)
 	} else {
 		l.Printf("error in %s (failing line not found, stack trace says func call is at line %d)", filepathShort, debugLineNumber)
 	}
 
-	l.PrintSource(lines, PrintSourceOptions{
-		FuncLine: funcLine,
-		Highlighted: map[int][]int{
-			failingLineIndex: {columnStart, columnEnd},
-		},
-		StartLine: minLine,
-		EndLine:   maxLine,
-	})
+	l.PrintSource(ex.Lines, ex.printSourceOptions())
 }
 
 // PrintSource prints source code based on opts
@@ -179,69 +414,154 @@ func (l *logger) PrintSource(lines []string, opts PrintSourceOptions) {
 			continue
 		}
 
-		hlStart := max(opts.Highlighted[i][0], 0)          //highlight column start
-		hlEnd := min(opts.Highlighted[i][1], len(lines)-1) //highlight column end
+		hlStart := min(max(opts.Highlighted[i][0], 0), len(lines[i]))         //highlight column start
+		hlEnd := max(min(opts.Highlighted[i][1], len(lines[i])-1), hlStart-1) //highlight column end
 		l.Printf("%d: %s%s%s", i+1, color.YellowString(lines[i][:hlStart]), color.RedString(lines[i][hlStart:hlEnd+1]), color.YellowString(lines[i][hlEnd+1:]))
 	}
 }
 
+//Doctor tells whether the config has problems (see Config.Validate), which are logged in debug mode.
+//It does not modify the config: fix it with Config.AutoFix and SetConfig.
 func (l *logger) Doctor() (neededDoctor bool) {
-	neededDoctor = false
-
-	if l.config.PrintFunc == nil {
-		neededDoctor = true
-		logrus.Debug("PrintFunc not set for this logger. Replacing with DefaultLoggerPrintFunc.")
-		l.config.PrintFunc = DefaultLoggerPrintFunc
-	}
-
-	if l.config.LinesBefore < 0 {
-		neededDoctor = true
-		logrus.Debugf("LinesBefore is '%d' but should not be <0. Setting to 0.", l.config.LinesBefore)
-		l.config.LinesBefore = 0
+	diagnostics := l.Config().Validate()
+	for _, d := range diagnostics {
+		debugf("errlog: config problem: %s", d)
 	}
+	return len(diagnostics) > 0
+}
 
-	if l.config.LinesAfter < 0 {
-		neededDoctor = true
-		logrus.Debugf("LinesAfters is '%d' but should not be <0. Setting to 0.", l.config.LinesAfter)
-		l.config.LinesAfter = 0
+//diagnose logs the problems of cfg in debug mode, before a logger uses it as is.
+//Sources are not checked, to keep NewLogger and SetConfig fast.
+func diagnose(cfg *Config) {
+	if !debugMode {
+		return
 	}
-
-	if neededDoctor && !debugMode {
-		logrus.Warn("errlog: Doctor() has detected and fixed some problems on your logger configuration. It might have modified your configuration. Check logs by enabling debug. 'errlog.SetDebugMode(true)'.")
+	for _, d := range cfg.validate(false) {
+		debugf("errlog: config problem: %s", d)
 	}
-
-	return
 }
 
 func (l *logger) printStack(stLines []StackTraceItem) {
-	for i := len(stLines) - 1; i >= 0; i-- {
+	frames := filterStack(stLines, l.Config().StackFilter)
+	for i := len(frames) - 1; i >= 0; i-- {
 		padding := ""
-		if !l.config.DisableStackIndentation {
-			for j := 0; j < len(stLines)-1-i; j++ {
+		if !l.Config().DisableStackIndentation {
+			for j := 0; j < len(frames)-1-i; j++ {
 				padding += "  "
 			}
 		}
-		l.Printf("%s (%s:%d)", stLines[i].CallingObject, stLines[i].SourcePathRef, stLines[i].SourceLineRef)
+		if frames[i].collapsed > 0 {
+			l.Printf("... %d frames in %s", frames[i].collapsed, frames[i].module)
+			continue
+		}
+		l.Printf("%s (%s:%d)%s", formatCall(frames[i].item), frames[i].item.SourcePathRef, frames[i].item.SourceLineRef, inlinedMark(frames[i].item))
+	}
+}
+
+//inlinedMark returns the mark appended to inlined frames when printing stack trace
+func inlinedMark(item StackTraceItem) string {
+	if !item.Inlined {
+		return ""
 	}
+	return color.BlueString(" (inlined)")
 }
 
 //Printf is the function used to log
 func (l *logger) Printf(format string, data ...interface{}) {
-	l.config.PrintFunc(format, data...)
+	printFunc := l.Config().PrintFunc
+	if printFunc == nil { //configs are not fixed (see Config.AutoFix)
+		printFunc = DefaultLoggerPrintFunc
+	}
+	printFunc(format, data...)
 }
 
 //Overload adds depths to remove when parsing next stack trace
+//
+//Deprecated: the depth is shared by every goroutine using the logger, and consumed by the next Debug call of any of
+//them. Use DebugStack with Stack(skip) instead.
 func (l *logger) Overload(amount int) {
-	l.stackDepthOverload += amount
+	atomic.AddInt32(&l.stackDepthOverload, int32(amount))
 }
 
 func (l *logger) SetConfig(cfg *Config) {
-	l.config = cfg
-	l.Doctor()
+	l.Close()
+	diagnose(cfg)
+	l.config.Store(cfg)
+}
+
+//allowReport tells whether r should be printed, exported and sent to sinks, according to Config.RateLimit
+func (l *logger) allowReport(r *Report) bool {
+	if l.Config().RateLimit == nil {
+		return true
+	}
+	l.mu.Lock()
+	if l.limiter == nil {
+		l.limiter = newRateLimiter(*l.Config().RateLimit, func(format string, data ...interface{}) {
+			l.printMu.Lock() //summaries are printed by a timer, maybe while a report is
+			defer l.printMu.Unlock()
+			l.Printf(format, data...)
+		})
+	}
+	limiter := l.limiter
+	l.mu.Unlock()
+
+	fingerprint := rateLimitFingerprint(r.Err, r.Stack, limiter.config.StackDepth)
+	summary := fmt.Sprintf("Error in %s: %s (%s:%d)", r.Stack[0].CallingObject, r.Err, r.Stack[0].SourcePathRef, r.Stack[0].SourceLineRef)
+	return limiter.allow(r.Time, fingerprint, summary)
+}
+
+//Close delivers queued reports, stops the async workers, flushes pending summaries of repeated reports and stops the rate limiter timer
+func (l *logger) Close() error {
+	l.mu.Lock()
+	queue := l.queue
+	l.queue = nil
+	l.mu.Unlock()
+	if queue != nil {
+		queue.close()
+		dropped, _ := queue.stats()
+		atomic.AddUint64(&l.dropped, dropped)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limiter != nil {
+		l.limiter.close()
+		l.limiter = nil
+	}
+	return nil
+}
+
+//Flush waits until the reports queued in async mode are delivered, or until ctx is done
+func (l *logger) Flush(ctx context.Context) error {
+	l.mu.Lock()
+	queue := l.queue
+	l.mu.Unlock()
+	if queue == nil {
+		return nil
+	}
+	return queue.flush(ctx)
+}
+
+//Stats returns how many reports were delivered and dropped
+func (l *logger) Stats() Stats {
+	stats := Stats{
+		Delivered: atomic.LoadUint64(&l.delivered),
+		Dropped:   atomic.LoadUint64(&l.dropped),
+	}
+
+	l.mu.Lock()
+	queue := l.queue
+	l.mu.Unlock()
+	if queue != nil {
+		dropped, pending := queue.stats()
+		stats.Dropped += dropped
+		stats.Pending = pending
+	}
+	return stats
 }
 
 func (l *logger) Config() *Config {
-	return l.config
+	return l.config.Load()
 }
 
 func (l *logger) SetMode(mode int) bool {
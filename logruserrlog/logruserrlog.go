@@ -0,0 +1,121 @@
+// Package logruserrlog sends the debug messages of errlog to logrus
+//
+// errlog logs its internal debug messages with log/slog when debug mode is on. Use a logrus logger instead with:
+//
+//	logrus.SetLevel(logrus.DebugLevel)
+//	logruserrlog.Use(logrus.StandardLogger())
+//	errlog.SetDebugMode(true)
+//
+// Handler can also be used as the slog.Handler of any other slog.Logger.
+package logruserrlog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+	"github.com/snwfdhmp/errlog"
+)
+
+//Use sets l as the logger of the debug messages of errlog (see errlog.SetDebugLogger)
+func Use(l *logrus.Logger) {
+	errlog.SetDebugLogger(slog.New(NewHandler(l)))
+}
+
+//Handler is a slog.Handler logging records with a logrus logger. Attributes become logrus fields.
+type Handler struct {
+	logger *logrus.Logger
+	fields logrus.Fields
+	prefix string //group prefix of the keys of the next attributes (eg: "request.")
+}
+
+//NewHandler returns a Handler logging with l. If l is nil, logrus.StandardLogger() is used.
+func NewHandler(l *logrus.Logger) *Handler {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+	return &Handler{logger: l, fields: logrus.Fields{}}
+}
+
+//Enabled tells whether the logrus logger logs records of level
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(logrusLevel(level))
+}
+
+//Handle logs r with the logrus logger
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(logrus.Fields, len(h.fields)+r.NumAttrs())
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(fields, h.prefix, a)
+		return true
+	})
+
+	entry := h.logger.WithContext(ctx).WithFields(fields)
+	if !r.Time.IsZero() {
+		entry = entry.WithTime(r.Time)
+	}
+	entry.Log(logrusLevel(r.Level), r.Message)
+	return nil
+}
+
+//WithAttrs returns a Handler adding attrs to every record
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := h.clone()
+	for _, a := range attrs {
+		addAttr(child.fields, child.prefix, a)
+	}
+	return child
+}
+
+//WithGroup returns a Handler prefixing the keys of the next attributes with name
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	child := h.clone()
+	child.prefix += name + "."
+	return child
+}
+
+//clone returns a copy of h which can be modified
+func (h *Handler) clone() *Handler {
+	fields := make(logrus.Fields, len(h.fields))
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	return &Handler{logger: h.logger, fields: fields, prefix: h.prefix}
+}
+
+//addAttr adds a to fields, flattening groups into dotted keys
+func addAttr(fields logrus.Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		fields[prefix+a.Key] = a.Value.Any()
+		return
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, child := range a.Value.Group() {
+		addAttr(fields, prefix, child)
+	}
+}
+
+//logrusLevel returns the logrus level of a slog level
+func logrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	}
+	return logrus.DebugLevel
+}
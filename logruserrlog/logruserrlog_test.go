@@ -0,0 +1,49 @@
+package logruserrlog
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/snwfdhmp/errlog"
+)
+
+func TestHandler(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.InfoLevel)
+
+	l := slog.New(NewHandler(logger)).With("app", "x").WithGroup("request")
+	l.Debug("hidden")
+	l.Warn("slow", "id", 42, slog.Group("user", "name", "bob"))
+
+	if len(hook.Entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(hook.Entries))
+	}
+	entry := hook.LastEntry()
+	if entry.Level != logrus.WarnLevel || entry.Message != "slow" {
+		t.Errorf("logged %s %q, want warning \"slow\"", entry.Level, entry.Message)
+	}
+	if entry.Data["app"] != "x" || entry.Data["request.id"] != int64(42) || entry.Data["request.user.name"] != "bob" {
+		t.Errorf("unexpected fields %v", entry.Data)
+	}
+}
+
+func TestUse(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	Use(logger)
+	errlog.SetDebugMode(true)
+	defer func() {
+		errlog.SetDebugMode(false)
+		errlog.SetDebugLogger(nil)
+	}()
+
+	errlog.NewLogger(&errlog.Config{LinesBefore: -1})
+
+	entry := hook.LastEntry()
+	if entry == nil || !strings.Contains(entry.Message, "LinesBefore") {
+		t.Errorf("errlog debug messages were not sent to logrus: %v", hook.AllEntries())
+	}
+}
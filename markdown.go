@@ -0,0 +1,176 @@
+package errlog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+//Renderer writes reports in a given format
+type Renderer interface {
+	Render(w io.Writer, r *Report) error
+}
+
+//MarkdownRenderer renders reports as GitHub flavored Markdown, for pasting them into issues and pull requests.
+//As code fences cannot carry colors, the failing call is marked with a `// <-- failing call` comment.
+type MarkdownRenderer struct {
+	LinesBefore int          //How many lines to render *before* the Debug call in the excerpt
+	LinesAfter  int          //How many lines to render *after* the Debug call in the excerpt
+	StackFilter *StackFilter //Which frames of the stack trace to render (nil renders them all)
+}
+
+//NewMarkdownRenderer returns a MarkdownRenderer using the excerpt size and stack filter of cfg, or of DefaultLogger if cfg is nil
+func NewMarkdownRenderer(cfg *Config) *MarkdownRenderer {
+	if cfg == nil {
+		cfg = DefaultLogger.Config()
+	}
+	return &MarkdownRenderer{
+		LinesBefore: cfg.LinesBefore,
+		LinesAfter:  cfg.LinesAfter,
+		StackFilter: cfg.StackFilter,
+	}
+}
+
+//Render writes r as Markdown: a heading with the error, the source excerpt, the variables and a collapsible stack trace
+func (m *MarkdownRenderer) Render(w io.Writer, r *Report) error {
+	var b strings.Builder
+
+	function := "unknown"
+	if len(r.Stack) > 0 {
+		function = r.Stack[0].CallingObject
+	}
+	fmt.Fprintf(&b, "### Error in %s: %s\n\n", markdownCode(function), markdownEscape(r.Message))
+	fmt.Fprintf(&b, "%s · fingerprint %s\n\n", markdownCode(r.ErrorType), markdownCode(r.Fingerprint))
+
+	if len(r.Stack) > 0 {
+		m.renderSource(&b, r.Stack[0])
+	}
+
+	if len(r.Vars) > 0 {
+		b.WriteString("**Variables**\n\n")
+		for _, v := range r.Vars {
+			fmt.Fprintf(&b, "- %s = %s\n", markdownCode(v.Name), markdownCode(v.Value))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Stack) > 0 {
+		m.renderStack(&b, "Stack trace", r.Stack)
+	}
+
+	if len(r.CreatedBy) > 0 {
+		b.WriteString("\n**Goroutine created by**\n\n")
+		m.renderSource(&b, r.CreatedBy[0])
+		m.renderStack(&b, "Goroutine created by", r.CreatedBy)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//renderSource writes the excerpt around the call site of item in a go code fence
+func (m *MarkdownRenderer) renderSource(b *strings.Builder, item StackTraceItem) {
+	location := fmt.Sprintf("%s:%d", shortSourcePath(item.SourcePathRef), item.SourceLineRef)
+
+	ex, err := loadSourceExcerpt(item.SourcePathRef, item.SourceLineRef, max(m.LinesBefore, 0), max(m.LinesAfter, 0))
+	if err != nil {
+		fmt.Fprintf(b, "%s (source not available: %s)\n\n", markdownCode(location), markdownEscape(err.Error()))
+		return
+	}
+
+	//mark the failing line, or the line of the stack trace if it cannot be found
+	markedLine, marker := ex.FailingLine+1, "// <-- failing call"
+	if ex.FailingLine != -1 && ex.FailingLine < ex.StartLine { // the marker is all we have, it must be shown
+		ex.StartLine = ex.FailingLine
+	}
+	if ex.FailingLine == -1 {
+		markedLine, marker = ex.DebugLine, "// <-- stack trace points here"
+	}
+
+	excerpt := ex.Excerpt()
+	width := 0
+	for _, line := range excerpt {
+		width = max(width, len(fmt.Sprint(line.Number)))
+	}
+
+	var code strings.Builder
+	for _, line := range excerpt {
+		if line.Number == 0 {
+			code.WriteString("...\n")
+			continue
+		}
+		fmt.Fprintf(&code, "%*d: %s", width, line.Number, line.Text)
+		if line.Number == markedLine {
+			code.WriteString(" " + marker)
+		}
+		code.WriteString("\n")
+	}
+
+	fmt.Fprintf(b, "%s\n\n", markdownCode(location))
+	b.WriteString(markdownFence("go", code.String()))
+	b.WriteString("\n")
+}
+
+//renderStack writes the stack trace, outermost frame first like printStack, in a collapsible details block
+func (m *MarkdownRenderer) renderStack(b *strings.Builder, title string, stack []StackTraceItem) {
+	frames := filterStack(stack, m.StackFilter)
+
+	var code strings.Builder
+	for i := len(frames) - 1; i >= 0; i-- {
+		if frames[i].collapsed > 0 {
+			fmt.Fprintf(&code, "... %d frames in %s\n", frames[i].collapsed, frames[i].module)
+			continue
+		}
+		item := frames[i].item
+		inlined := ""
+		if item.Inlined {
+			inlined = " (inlined)"
+		}
+		fmt.Fprintf(&code, "%s (%s:%d)%s\n", formatCall(item), shortSourcePath(item.SourcePathRef), item.SourceLineRef, inlined)
+	}
+
+	fmt.Fprintf(b, "<details>\n<summary>%s (%d frames)</summary>\n\n", title, len(stack))
+	b.WriteString(markdownFence("", code.String()))
+	b.WriteString("\n</details>\n")
+}
+
+//markdownFence wraps code in a fence longer than any backtick run it contains
+func markdownFence(lang, code string) string {
+	fence := strings.Repeat("`", max(3, longestRun(code, '`')+1))
+	return fence + lang + "\n" + code + fence + "\n"
+}
+
+//markdownCode wraps s in an inline code span, which cannot be broken by the backticks of s
+func markdownCode(s string) string {
+	fence := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+//markdownEscape escapes the characters of s which Markdown would interpret
+func markdownEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>#|~", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+//longestRun returns the length of the longest run of c in s
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
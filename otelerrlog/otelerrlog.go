@@ -0,0 +1,64 @@
+// Package otelerrlog records errlog reports as events of OpenTelemetry spans
+//
+// Set a SpanExporter as exporter of a logger, and debug errors with the context of the current span:
+//
+//	errlog.DefaultLogger.Config().Exporter = otelerrlog.NewSpanExporter()
+//
+//	func handle(ctx context.Context) {
+//		ctx, span := tracer.Start(ctx, "handle")
+//		defer span.End()
+//		if err := do(ctx); errlog.DebugContext(ctx, err) {
+//			return
+//		}
+//	}
+//
+// The span gets an "exception" event with the failing line and the source excerpt of the Debug call (see errlog.NewEvent).
+package otelerrlog
+
+import (
+	"context"
+
+	"github.com/snwfdhmp/errlog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//SpanExporter is an errlog.Exporter adding events to the span of the context given to DebugContext
+type SpanExporter struct {
+	SetErrorStatus bool //Shall we set the status of the span to Error with the error message ? yes/no
+}
+
+//NewSpanExporter returns a SpanExporter which only adds events
+func NewSpanExporter() *SpanExporter {
+	return &SpanExporter{}
+}
+
+//Export adds event to the span of ctx. It does nothing if ctx has no recording span.
+func (e *SpanExporter) Export(ctx context.Context, event errlog.Event) error {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return nil
+	}
+
+	attributes := make([]attribute.KeyValue, 0, len(event.Attributes))
+	message := ""
+	for _, a := range event.Attributes {
+		switch v := a.Value.(type) {
+		case int:
+			attributes = append(attributes, attribute.Int(a.Key, v))
+		case string:
+			attributes = append(attributes, attribute.String(a.Key, v))
+			if a.Key == "exception.message" {
+				message = v
+			}
+		}
+	}
+
+	span.AddEvent(event.Name, trace.WithTimestamp(event.Time), trace.WithAttributes(attributes...))
+	if e.SetErrorStatus {
+		span.SetStatus(codes.Error, message)
+	}
+
+	return nil
+}
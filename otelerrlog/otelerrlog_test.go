@@ -0,0 +1,47 @@
+package otelerrlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/snwfdhmp/errlog"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanExporter(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	l := errlog.NewLogger(&errlog.Config{
+		PrintFunc: func(format string, data ...interface{}) {},
+		Exporter:  &SpanExporter{SetErrorStatus: true},
+	}).(errlog.ContextDebugger)
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+	err := errors.New("export me")
+	l.DebugContext(ctx, err)
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || len(spans[0].Events()) != 1 {
+		t.Fatalf("recorded %d spans, want 1 with 1 event", len(spans))
+	}
+	event := spans[0].Events()[0]
+	if event.Name != "exception" {
+		t.Errorf("event name = %q, want exception", event.Name)
+	}
+
+	attributes := make(map[string]string)
+	for _, a := range event.Attributes {
+		attributes[string(a.Key)] = a.Value.Emit()
+	}
+	if attributes["exception.message"] != "export me" || attributes["errlog.failing_line.code"] != `err := errors.New("export me")` {
+		t.Errorf("unexpected attributes: %v", attributes)
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("span status = %v, want Error", spans[0].Status().Code)
+	}
+}
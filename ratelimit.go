@@ -0,0 +1,168 @@
+package errlog
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimitStackDepth = 3
+)
+
+//RateLimitConfig holds the configuration for deduplicating repeated reports (see Config.RateLimit)
+//
+//Reports are grouped by fingerprint: the error type, the failing file:line and the top StackDepth frames.
+//The first Burst reports of a fingerprint are printed in full, exported and sent to Config.Sink, the next ones are
+//only counted and summarized as "repeated N times in D" (printed with Config.PrintFunc) once the window is over, or
+//when Close is called. Config.Aggregator gets every report, to count them all.
+type RateLimitConfig struct {
+	Window      time.Duration //Period during which repeated reports are counted instead of reported
+	Burst       int           //How many full reports of a fingerprint to deliver per window (<1 means 1)
+	SampleEvery int           //Deliver one repeated report in full every SampleEvery occurrences (0 disables sampling)
+	StackDepth  int           //How many top frames are part of the fingerprint (<1 means 3)
+}
+
+//rateLimiter counts reports per fingerprint and periodically prints summaries of suppressed ones
+type rateLimiter struct {
+	mu      sync.Mutex
+	config  RateLimitConfig
+	printf  func(format string, data ...interface{})
+	now     func() time.Time //clock, replaced in tests
+	entries map[string]*rateLimitEntry
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+//rateLimitEntry holds the state of one fingerprint for the current window
+type rateLimitEntry struct {
+	summary     string    //short description of the first report, used in summaries
+	windowStart time.Time //when the current window started
+	printed     int       //how many full reports were printed during the current window
+	repeated    int       //how many reports exceeded Burst during the current window
+}
+
+//newRateLimiter creates a rateLimiter and starts its flushing timer
+func newRateLimiter(cfg RateLimitConfig, printf func(format string, data ...interface{})) *rateLimiter {
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	if cfg.StackDepth < 1 {
+		cfg.StackDepth = defaultRateLimitStackDepth
+	}
+
+	r := &rateLimiter{
+		config:  cfg,
+		printf:  printf,
+		now:     time.Now,
+		entries: make(map[string]*rateLimitEntry),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go r.run()
+
+	return r
+}
+
+//run flushes expired windows every Window until close is called
+func (r *rateLimiter) run() {
+	defer close(r.done)
+
+	if r.config.Window <= 0 {
+		<-r.stop
+		return
+	}
+
+	ticker := time.NewTicker(r.config.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case now := <-ticker.C:
+			r.flush(now, false)
+		}
+	}
+}
+
+//allow registers a report made at now and returns whether it should be delivered in full
+func (r *rateLimiter) allow(now time.Time, fingerprint, summary string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[fingerprint]
+	if !ok {
+		r.entries[fingerprint] = &rateLimitEntry{summary: summary, windowStart: now, printed: 1}
+		return true
+	}
+
+	if r.config.Window > 0 && now.Sub(e.windowStart) >= r.config.Window {
+		r.printSummary(e, now)
+		e.windowStart, e.printed, e.repeated = now, 1, 0
+		return true
+	}
+
+	if e.printed < r.config.Burst {
+		e.printed++
+		return true
+	}
+
+	e.repeated++
+	return r.config.SampleEvery > 0 && e.repeated%r.config.SampleEvery == 0
+}
+
+//flush prints summaries of windows that are over, or of every window if force is true. Idle entries are forgotten.
+func (r *rateLimiter) flush(now time.Time, force bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for fingerprint, e := range r.entries {
+		if !force && now.Sub(e.windowStart) < r.config.Window {
+			continue
+		}
+		if e.repeated == 0 {
+			delete(r.entries, fingerprint)
+			continue
+		}
+		r.printSummary(e, now)
+		e.windowStart, e.printed, e.repeated = now, 0, 0
+	}
+}
+
+//printSummary prints how many times the report of e has been repeated during the current window. r.mu must be held.
+func (r *rateLimiter) printSummary(e *rateLimitEntry, now time.Time) {
+	if e.repeated == 0 {
+		return
+	}
+	times := "times"
+	if e.repeated == 1 {
+		times = "time"
+	}
+	r.printf("%s repeated %d %s in %s", e.summary, e.repeated, times, now.Sub(e.windowStart).Round(time.Millisecond))
+}
+
+//close stops the flushing timer and prints every pending summary
+func (r *rateLimiter) close() {
+	close(r.stop)
+	<-r.done
+	r.flush(r.now(), true)
+}
+
+//rateLimitFingerprint identifies a report by its error type, its failing file:line and the top depth frames of its stack
+func rateLimitFingerprint(uErr error, stLines []StackTraceItem, depth int) string {
+	parts := []string{fmt.Sprintf("%T", uErr)}
+	if len(stLines) > 0 {
+		parts = append(parts, fmt.Sprintf("%s:%d", stLines[0].SourcePathRef, stLines[0].SourceLineRef))
+	}
+	for i := 0; i < depth && i < len(stLines); i++ {
+		parts = append(parts, stLines[i].CallingObject)
+	}
+
+	sum := sha1.Sum([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
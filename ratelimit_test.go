@@ -0,0 +1,103 @@
+package errlog
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	cases := []struct {
+		name      string
+		config    RateLimitConfig
+		elapsed   []time.Duration //time elapsed before each report
+		allowed   []bool
+		summaries []string
+	}{
+		{
+			name:      "burst",
+			config:    RateLimitConfig{Window: time.Hour, Burst: 2},
+			elapsed:   []time.Duration{0, time.Second, time.Second, time.Second},
+			allowed:   []bool{true, true, false, false},
+			summaries: []string{"failed repeated 2 times in 3s"},
+		},
+		{
+			name:      "burst defaults to 1",
+			config:    RateLimitConfig{Window: time.Hour},
+			elapsed:   []time.Duration{0, 0},
+			allowed:   []bool{true, false},
+			summaries: []string{"failed repeated 1 time in 0s"},
+		},
+		{
+			name:      "window",
+			config:    RateLimitConfig{Window: time.Minute, Burst: 1},
+			elapsed:   []time.Duration{0, 30 * time.Second, 31 * time.Second, time.Second},
+			allowed:   []bool{true, false, true, false},
+			summaries: []string{"failed repeated 1 time in 1m1s", "failed repeated 1 time in 1s"},
+		},
+		{
+			name:      "window without repeats",
+			config:    RateLimitConfig{Window: time.Minute, Burst: 1},
+			elapsed:   []time.Duration{0, 2 * time.Minute},
+			allowed:   []bool{true, true},
+			summaries: nil,
+		},
+		{
+			name:      "sample",
+			config:    RateLimitConfig{Window: time.Hour, Burst: 1, SampleEvery: 2},
+			elapsed:   []time.Duration{0, 0, 0, 0, 0},
+			allowed:   []bool{true, false, true, false, true},
+			summaries: []string{"failed repeated 4 times in 0s"},
+		},
+		{
+			name:      "no window",
+			config:    RateLimitConfig{Burst: 1},
+			elapsed:   []time.Duration{0, time.Hour, time.Hour},
+			allowed:   []bool{true, false, false},
+			summaries: []string{"failed repeated 2 times in 2h0m0s"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var summaries []string
+			r := newRateLimiter(c.config, func(format string, data ...interface{}) {
+				summaries = append(summaries, fmt.Sprintf(format, data...))
+			})
+			now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+			r.now = func() time.Time { return now }
+
+			var allowed []bool
+			for _, d := range c.elapsed {
+				now = now.Add(d)
+				allowed = append(allowed, r.allow(now, "fingerprint", "failed"))
+			}
+			r.close()
+
+			if !reflect.DeepEqual(allowed, c.allowed) {
+				t.Errorf("allow() = %v, want %v", allowed, c.allowed)
+			}
+			if !reflect.DeepEqual(summaries, c.summaries) {
+				t.Errorf("summaries = %q, want %q", summaries, c.summaries)
+			}
+		})
+	}
+}
+
+func TestRateLimitFingerprint(t *testing.T) {
+	stack := []StackTraceItem{
+		{CallingObject: "main.save", SourcePathRef: "/src/main.go", SourceLineRef: 12},
+		{CallingObject: "main.main", SourcePathRef: "/src/main.go", SourceLineRef: 5},
+	}
+	moved := []StackTraceItem{stack[0], stack[1]}
+	moved[0].SourceLineRef = 13
+
+	fingerprint := rateLimitFingerprint(fmt.Errorf("id %d", 1), stack, 3)
+	if other := rateLimitFingerprint(fmt.Errorf("id %d", 2), stack, 3); other != fingerprint {
+		t.Error("fingerprint depends on the error message")
+	}
+	if other := rateLimitFingerprint(fmt.Errorf("id %d", 1), moved, 3); other == fingerprint {
+		t.Error("fingerprint does not depend on the failing line")
+	}
+}
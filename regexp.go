@@ -3,12 +3,13 @@ package errlog
 import (
 	"fmt"
 	"regexp"
+	"runtime"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
-
-	"github.com/fatih/color"
-	"github.com/sirupsen/logrus"
+	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -18,28 +19,152 @@ var (
 		Unfortunately, I didn't check against other code formatting tools, so it may require some evolution.
 		Feel free to create an issue or send a PR.
 	*/
-	regexpParseStack                 = regexp.MustCompile(`((?:(?:[a-zA-Z._-]+)[/])*(?:[*a-zA-Z0-9_]*\.)+[a-zA-Z0-9_]+)\(((?:(?:0x[0-9a-f]+)|(?:...)[,\s]*)+)*\)[\s]+([/:\-a-zA-Z0-9\._]+)[:]([0-9]+)[\s](?:\+0x([0-9a-f]+))*`)
-	regexpHexNumber                  = regexp.MustCompile(`0x[0-9a-f]+`)
-	regexpFuncLine                   = regexp.MustCompile(`^func[\s][a-zA-Z0-9]+[(](.*)[)][\s]*{`)
-	regexpParseDebugLineFindFunc     = regexp.MustCompile(`[\.]Debug[\(](.*)[/)]`)
-	regexpParseDebugLineParseVarName = regexp.MustCompile(`[\.]Debug[\(](.*)\)`)
-	regexpFindVarDefinition          = func(varName string) *regexp.Regexp {
-		return regexp.MustCompile(fmt.Sprintf(`%s[\s\:]*={1}([\s]*[a-zA-Z0-9\._]+)`, varName))
+	regexpParseStack             = regexp.MustCompile(`(?m)^((?:[^\s()]|\(\*?[^\s()]+\))+)\((.*)\)\n\t(.+)[:]([0-9]+)(?:[\s]\+0x([0-9a-f]+))?$`) // func name (with (*T) receivers and [...] type params), args, then file:line +0xoffset on next line
+	regexpHexNumber              = regexp.MustCompile(`0x[0-9a-f]+\??`)                                                                          // trailing '?' marks possibly inaccurate words (see runtime docs)
+	regexpFuncLine               = regexp.MustCompile(`^func[\s](?:[(][^)]*[)][\s])?[a-zA-Z0-9_]+(?:\[.*\])?[(](.*)[)].*{`)                      // funcs, methods and generic funcs
+	regexpParseDebugLineFindFunc = regexp.MustCompile(`[\.]Debug[\(](.*)[/)]`)
+	regexpIdentifier             = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	regexpReturnStatement        = regexp.MustCompile(`(?:^|[\s{;])return[\s]+(\S)`) // captures the first char of the returned values
+	regexpFindVarDefinition      = func(varName string) *regexp.Regexp {
+		return regexp.MustCompile(fmt.Sprintf(`%s[\s\:]*={1}([\s]*[a-zA-Z0-9\._]+)`, regexp.QuoteMeta(varName)))
 	}
 )
 
+var (
+	//debugFuncs are the functions whose calls report an error, by name, with the index of the error in their args
+	debugFuncs   = map[string]int{"Debug": 0, "DebugVars": 0, "DebugMap": 0, "DebugContext": 1}
+	debugFuncsMu sync.Mutex
+	//regexpParseDebugLineFindDebugFunc matches calls of debugFuncs up to their opening bracket, capturing the name
+	regexpParseDebugLineFindDebugFunc atomic.Pointer[regexp.Regexp]
+	//debugFuncsArgIndex is a copy of debugFuncs, read without holding debugFuncsMu
+	debugFuncsArgIndex atomic.Pointer[map[string]int]
+)
+
+func init() {
+	storeDebugFuncs()
+}
+
+//RegisterDebugFunc registers a function reporting errors (eg: Check for errlogtest.Check(t, err)), so that the line
+//which produced the error is found from its calls like from Debug calls. argIndex is the index of the error in its args.
+func RegisterDebugFunc(name string, argIndex int) {
+	debugFuncsMu.Lock()
+	defer debugFuncsMu.Unlock()
+	debugFuncs[name] = argIndex
+	storeDebugFuncs()
+}
+
+//storeDebugFuncs publishes the regexp matching the calls of debugFuncs, and the index of their error arg.
+//debugFuncsMu must be held, except during init.
+func storeDebugFuncs() {
+	names := make([]string, 0, len(debugFuncs))
+	argIndex := make(map[string]int, len(debugFuncs))
+	for name, i := range debugFuncs {
+		names = append(names, regexp.QuoteMeta(name))
+		argIndex[name] = i
+	}
+	sort.Strings(names)
+
+	regexpParseDebugLineFindDebugFunc.Store(regexp.MustCompile(`[\.](` + strings.Join(names, "|") + `)[\(]`))
+	debugFuncsArgIndex.Store(&argIndex)
+}
+
+//debugCallVar returns the name of the variable given as error to the first call of a debug func in expr
+//(eg: err for errlog.DebugContext(ctx, err)). ok is false if there is no such call, or if the error is not a
+//variable (eg: errlog.Debug(fmt.Errorf("cannot load: %w", err))).
+func debugCallVar(expr string) (varName string, ok bool) {
+	match := regexpParseDebugLineFindDebugFunc.Load().FindStringSubmatchIndex(expr)
+	if match == nil {
+		return "", false
+	}
+	args := splitCallArgs(expr[match[1]:])
+	index := (*debugFuncsArgIndex.Load())[expr[match[2]:match[3]]]
+	if index >= len(args) || !regexpIdentifier.MatchString(args[index]) {
+		return "", false
+	}
+	return args[index], true
+}
+
+//splitCallArgs returns the args of a call, from the text following its opening bracket up to its closing one.
+//Commas in brackets, strings and runes do not split args (eg: f(a, "b, c", g(d, e)) has 3 args).
+func splitCallArgs(s string) []string {
+	var args []string
+	depth, start := 0, 0
+	var quote byte //opening quote of the string being read, 0 if none
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++ //escaped char
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case (c == ')' || c == ']' || c == '}') && depth > 0:
+			depth--
+		case c == ')':
+			return append(args, strings.TrimSpace(s[start:i]))
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(args, strings.TrimSpace(s[start:])) //call not closed
+}
+
 // StackTraceItem represents parsed information of a stack trace item
 type StackTraceItem struct {
 	CallingObject string
-	Args          []string
+	Args          []string // Raw words of the args, suffixed with '?' when the runtime flags them as possibly inaccurate
 	SourcePathRef string
 	SourceLineRef int
-	MysteryNumber int64 // don't know what this is, no documentation found, if you know please let me know via a PR !
+	MysteryNumber int64   // Deprecated: same as PCOffset, but -25 when absent. Kept for compatibility.
+	Params        []Param // Args paired with the parameters of the function, set when Config.DecodeStackArgs is true
+	PCOffset      int64   // Offset of PC from the entry of the function (the +0x1a of stack traces), -1 for inlined frames which have none
+	PC            uintptr // Program counter of the frame, 0 if unknown (eg: when parsed from text)
+	Entry         uintptr // Entry program counter of the function (of the caller for inlined frames), 0 if unknown
+	Inlined       bool    // Whether the function has been inlined in its caller
 }
 
+//parseStackTrace parses the stack trace of the current goroutine, and fills PC, Entry and Inlined from runtime.Callers
 func parseStackTrace(deltaDepth int) []StackTraceItem {
-	fmt.Println(string(debug.Stack()))
-	return parseAnyStackTrace(string(debug.Stack()), deltaDepth)
+	pcs := make([]uintptr, 128)
+	pcs = pcs[:runtime.Callers(2, pcs)] // skip runtime.Callers and parseStackTrace
+
+	stLines := parseAnyStackTrace(string(debug.Stack()), deltaDepth)
+	attachFrames(stLines, pcs)
+
+	return stLines
+}
+
+//attachFrames sets PC, Entry and Inlined of stLines from the frames of pcs, matching them by function name and line
+func attachFrames(stLines []StackTraceItem, pcs []uintptr) {
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames(pcs)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+
+	j := 0
+	for i := range stLines {
+		for k := j; k < len(frames); k++ {
+			if frames[k].Function != stLines[i].CallingObject || frames[k].Line != stLines[i].SourceLineRef {
+				continue
+			}
+			stLines[i].PC = frames[k].PC
+			stLines[i].Entry = frames[k].Entry
+			stLines[i].Inlined = frames[k].Func == nil // the runtime has no Func for inlined frames
+			j = k + 1
+			break
+		}
+	}
 }
 
 func parseAnyStackTrace(stackStr string, deltaDepth int) []StackTraceItem {
@@ -48,6 +173,23 @@ func parseAnyStackTrace(stackStr string, deltaDepth int) []StackTraceItem {
 		return nil
 	}
 	stack := strings.Join(stackArr[2*(2+deltaDepth):], "\n") //get stack trace and reduce to desired size
+	return parseStackItems(stack)
+}
+
+//ParseStack parses the first goroutine of a stack trace, as printed by panics or debug.Stack, innermost frame first.
+//It lets you render the stack trace of a panic log like the one of a Debug call.
+func ParseStack(text string) []StackTraceItem {
+	if start := strings.Index(text, "\ngoroutine "); start != -1 {
+		text = text[start+1:]
+	}
+	if end := strings.Index(text, "\n\ngoroutine "); end != -1 { // GOTRACEBACK=all prints every goroutine
+		text = text[:end]
+	}
+	return parseStackItems(text)
+}
+
+//parseStackItems parses every frame of stack
+func parseStackItems(stack string) []StackTraceItem {
 	parsedRes := regexpParseStack.FindAllStringSubmatch(stack, -1)
 
 	sti := make([]StackTraceItem, len(parsedRes))
@@ -67,12 +209,19 @@ func parseAnyStackTrace(stackStr string, deltaDepth int) []StackTraceItem {
 			}
 		}
 
+		pcOffset := mysteryNumber
+		if mysteryNumberStr == "" {
+			pcOffset = -1
+		}
+
 		sti[i] = StackTraceItem{
 			CallingObject: parsedRes[i][1],
 			Args:          args,
 			SourcePathRef: parsedRes[i][3],
 			SourceLineRef: srcLine,
 			MysteryNumber: mysteryNumber,
+			PCOffset:      pcOffset,
+			Inlined:       mysteryNumberStr == "", // the runtime prints no offset for inlined frames
 		}
 	}
 
@@ -81,7 +230,7 @@ func parseAnyStackTrace(stackStr string, deltaDepth int) []StackTraceItem {
 
 //findFuncLine finds line where func is declared
 func findFuncLine(lines []string, lineNumber int) int {
-	for i := lineNumber; i > 0; i-- {
+	for i := min(lineNumber, len(lines)-1); i > 0; i-- {
 		if regexpFuncLine.Match([]byte(lines[i])) {
 			return i
 		}
@@ -90,37 +239,51 @@ func findFuncLine(lines []string, lineNumber int) int {
 	return -1
 }
 
-//findFailingLine finds line where <var> is defined, if Debug(<var>) is present on lines[debugLine]. funcLine serves as max
+//callExpression returns lines[line], joined with the next lines until every opened bracket is closed (for calls spanning multiple lines)
+func callExpression(lines []string, line int) string {
+	expr := lines[line]
+	opened := strings.Count(expr, "(") - strings.Count(expr, ")")
+	for i := line + 1; opened > 0 && i < len(lines); i++ {
+		expr += " " + strings.TrimSpace(lines[i])
+		opened += strings.Count(lines[i], "(") - strings.Count(lines[i], ")")
+	}
+	return expr
+}
+
+//findFailingLine finds line where <var> is defined, if Debug(<var>) is present on lines[debugLine]. funcLine serves as max.
+//If lines[debugLine] is a return statement instead (errors of goroutines started with Go), it is the failing line.
 func findFailingLine(lines []string, funcLine int, debugLine int) (failingLineIndex, columnStart, columnEnd int) {
 	failingLineIndex = -1 //init error flag
 
 	//find var name
-	reMatches := regexpParseDebugLineParseVarName.FindStringSubmatch(lines[debugLine-1])
-	if len(reMatches) < 2 {
+	varName, ok := debugCallVar(callExpression(lines, debugLine-1))
+	if !ok {
+		if index := regexpReturnStatement.FindStringSubmatchIndex(lines[debugLine-1]); index != nil {
+			return debugLine - 1, index[2], len(strings.TrimRight(lines[debugLine-1], " \t")) - 1
+		}
 		return
 	}
-	varName := reMatches[1]
 
 	//build regexp for finding var definition
 	reFindVar := regexpFindVarDefinition(varName)
 
 	//start to search for var definition
-	for i := debugLine; i >= funcLine && i > 0; i-- { // going reverse from debug line to funcLine
-		logrus.Debugf("%d: %s", i, lines[i]) // print line for debug
+	for i := min(debugLine, len(lines)-1); i >= funcLine && i > 0; i-- { // going reverse from debug line to funcLine
+		debugf("%d: %s", i, lines[i]) // print line for debug
 
 		// early skipping some cases
 		if strings.Trim(lines[i], " \n\t") == "" { // skip if line is blank
-			logrus.Debugf(color.BlueString("%d: ignoring blank line", i))
+			debugf("%d: ignoring blank line", i)
 			continue
 		} else if len(lines[i]) >= 2 && lines[i][:2] == "//" { // skip if line is a comment line (note: comments of type '/*' can be stopped inline and code may be placed after it, therefore we should pass line if '/*' starts the line)
-			logrus.Debugf(color.BlueString("%d: ignoring comment line", i))
+			debugf("%d: ignoring comment line", i)
 			continue
 		}
 
 		//search for var definition
 		index := reFindVar.FindStringSubmatchIndex(lines[i])
 		if index == nil { //if not found, continue searching with next line
-			logrus.Debugf(color.BlueString("%d: var definition not found for '%s' (regexp no match).", i, varName))
+			debugf("%d: var definition not found for '%s' (regexp no match).", i, varName)
 			continue
 		}
 		// At that point we found our definition
@@ -144,7 +307,7 @@ func findFailingLine(lines []string, funcLine int, debugLine int) (failingLineIn
 		}
 
 		if columnEnd == 0 { //columnEnd was not found
-			logrus.Debugf("Fixing value of columnEnd (0). Defaulting to end of failing line.")
+			debugf("Fixing value of columnEnd (0). Defaulting to end of failing line.")
 			columnEnd = len(lines[i]) - 1
 		}
 		return
@@ -0,0 +1,39 @@
+package errlog
+
+import (
+	"fmt"
+	"time"
+)
+
+//Report holds what errlog knows about an error passed to Debug
+type Report struct {
+	Time        time.Time        //When Debug was called
+	Err         error            `json:"-"` //Debugged error
+	ErrorType   string           //Go type of the error (eg: *errors.errorString)
+	Message     string           //Error message
+	Fingerprint string           //Group ID of the report (see Fingerprint)
+	Stack       []StackTraceItem //Stack trace of the Debug call, innermost frame first
+	CreatedBy   []StackTraceItem `json:",omitempty"` //Stack trace of the code which started the goroutine, for panics recovered by Go
+	Vars        []Var            //Named values given to DebugVars or DebugMap
+	Level       Level            //Level of the report, from Config.Level
+}
+
+//NewReport creates a report for err with the given stack trace, innermost frame first (eg: from ParseStack).
+//It lets you render errors which were not passed to Debug.
+func NewReport(err error, stack []StackTraceItem) *Report {
+	return newReport(err, stack, nil)
+}
+
+//newReport creates a report for uErr debugged with the given stack trace and named values
+func newReport(uErr error, stLines []StackTraceItem, vars []Var) *Report {
+	return &Report{
+		Time:        time.Now(),
+		Err:         uErr,
+		ErrorType:   fmt.Sprintf("%T", uErr),
+		Message:     uErr.Error(),
+		Fingerprint: Fingerprint(uErr, stLines),
+		Stack:       stLines,
+		Vars:        vars,
+		Level:       LevelError,
+	}
+}
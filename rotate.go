@@ -0,0 +1,265 @@
+package errlog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const rotateTimeFormat = "20060102T150405.000"
+
+//RotateConfig tells when a RotatingFileSink rotates its file and which rotated files it keeps
+type RotateConfig struct {
+	MaxSize    int64         //Rotate before the file grows beyond this many bytes (0 disables it)
+	MaxAge     time.Duration //Rotate once the file is older than this (0 disables it)
+	MaxBackups int           //How many rotated files to keep, the oldest are removed (0 keeps them all)
+	Compress   bool          //Shall we gzip rotated files ? yes/no
+}
+
+//RotatingFileSink appends rendered reports to a file, which is rotated by size and age. Rotated files are named
+//after the file with the time of rotation (eg: errlog.log.20261018T101500.000, .gz if compressed).
+//Rotating and compressing happen in Send: use Config.Async to keep them off the goroutine of the caller.
+type RotatingFileSink struct {
+	path     string
+	renderer Renderer
+	config   RotateConfig
+	now      func() time.Time //clock, replaced in tests
+
+	mu       sync.Mutex
+	file     *os.File  //nil if closed, or if it could not be reopened after a rotation
+	closed   bool      //whether Close was called
+	size     int64     //size of file
+	openedAt time.Time //when file was created, or last modified if it existed
+}
+
+//NewRotatingFileSink returns a RotatingFileSink appending reports rendered with renderer to the file at path
+//(eg: JSONRenderer{}, or NewTextRenderer(cfg, true) for plain text)
+func NewRotatingFileSink(path string, renderer Renderer, cfg RotateConfig) (*RotatingFileSink, error) {
+	s := &RotatingFileSink{
+		path:     path,
+		renderer: renderer,
+		config:   cfg,
+		now:      time.Now,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+//Send renders r to the file, rotating it first if needed. If the rotation fails, r is still written, to the file
+//which was being rotated or to a new one, and the error of the rotation is returned.
+func (s *RotatingFileSink) Send(r *Report) error {
+	var b strings.Builder
+	if err := s.renderer.Render(&b, r); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reopen(); err != nil {
+		return err
+	}
+	var rotateErr error
+	if s.shouldRotate(int64(b.Len())) {
+		rotateErr = s.rotate()
+		if s.file == nil { //closed by the failed rotation
+			if err := s.open(); err != nil {
+				return errors.Join(rotateErr, err)
+			}
+		}
+	}
+
+	n, err := io.WriteString(s.file, b.String())
+	s.size += int64(n)
+	return errors.Join(rotateErr, err)
+}
+
+//Rotate rotates the file now
+func (s *RotatingFileSink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reopen(); err != nil {
+		return err
+	}
+	return s.rotate()
+}
+
+//Close closes the file
+func (s *RotatingFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+//open opens the file for appending, creating it if needed
+func (s *RotatingFileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file, s.size, s.openedAt = file, info.Size(), s.now()
+	if info.Size() > 0 {
+		s.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+//reopen opens the file again if a rotation failed to, or returns os.ErrClosed if Close was called
+func (s *RotatingFileSink) reopen() error {
+	if s.closed {
+		return os.ErrClosed
+	}
+	if s.file == nil {
+		return s.open()
+	}
+	return nil
+}
+
+//shouldRotate tells whether the file must be rotated before writing n more bytes. Empty files are never rotated.
+func (s *RotatingFileSink) shouldRotate(n int64) bool {
+	if s.size == 0 {
+		return false
+	}
+	if s.config.MaxSize > 0 && s.size+n > s.config.MaxSize {
+		return true
+	}
+	return s.config.MaxAge > 0 && s.now().Sub(s.openedAt) >= s.config.MaxAge
+}
+
+//rotate renames the file, compresses it if needed, removes old rotated files, and opens a new file.
+//If the file cannot be renamed, it is reopened to keep appending to it.
+func (s *RotatingFileSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return err
+	}
+
+	rotated := s.path + "." + s.now().Format(rotateTimeFormat)
+	for i := 1; fileExists(rotated) || fileExists(rotated+".gz"); i++ {
+		rotated = fmt.Sprintf("%s.%s-%d", s.path, s.now().Format(rotateTimeFormat), i)
+	}
+	if err := os.Rename(s.path, rotated); err != nil {
+		if openErr := s.open(); openErr != nil {
+			return fmt.Errorf("cannot rotate: %s, cannot reopen: %s", err, openErr)
+		}
+		return err
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	if s.config.Compress {
+		if err := gzipFile(rotated); err != nil {
+			return fmt.Errorf("cannot compress %s: %s", rotated, err)
+		}
+	}
+
+	return s.removeOldBackups()
+}
+
+//removeOldBackups removes the oldest rotated files, keeping config.MaxBackups of them
+func (s *RotatingFileSink) removeOldBackups() error {
+	if s.config.MaxBackups < 1 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(s.path))
+	if err != nil {
+		return err
+	}
+	var backups []string
+	for _, entry := range entries {
+		if s.isBackup(entry.Name()) {
+			backups = append(backups, filepath.Join(filepath.Dir(s.path), entry.Name()))
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { //names end with the time of rotation
+		return strings.TrimSuffix(backups[i], ".gz") < strings.TrimSuffix(backups[j], ".gz")
+	})
+	for len(backups) > s.config.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+//isBackup tells whether name is the name of a file rotated by s (eg: errlog.log.20261018T101500.000-1.gz)
+func (s *RotatingFileSink) isBackup(name string) bool {
+	suffix, ok := strings.CutPrefix(name, filepath.Base(s.path)+".")
+	if !ok || len(suffix) < len(rotateTimeFormat) {
+		return false
+	}
+	if _, err := time.Parse(rotateTimeFormat, suffix[:len(rotateTimeFormat)]); err != nil {
+		return false
+	}
+
+	suffix = strings.TrimSuffix(suffix[len(rotateTimeFormat):], ".gz")
+	if suffix == "" {
+		return true
+	}
+	n, ok := strings.CutPrefix(suffix, "-")
+	return ok && n != "" && strings.Trim(n, "0123456789") == ""
+}
+
+//gzipFile compresses the file at path to path.gz, and removes it
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
+
+//fileExists tells whether there is a file at path
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -0,0 +1,150 @@
+package errlog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileSink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "errlog.log")
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	sink, err := NewRotatingFileSink(path, JSONRenderer{}, RotateConfig{MaxSize: 250, MaxAge: time.Hour, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sink.now = func() time.Time { return now }
+	if err := os.WriteFile(path+".bak", nil, 0644); err != nil { // not a backup, must be kept
+		t.Fatal(err)
+	}
+
+	send := func(msg string) {
+		t.Helper()
+		if err := sink.Send(&Report{Message: msg, Level: LevelError}); err != nil { // ~115 bytes each
+			t.Fatal(err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	send("first")
+	send("second")
+	send("third") // would exceed MaxSize: first and second are rotated at 10:00:02
+	now = now.Add(time.Hour)
+	send("fourth") // file is too old: third is rotated at 11:00:03
+	send("fifth")
+	send("sixth") // fourth and fifth are rotated at 11:00:05, the oldest backup is removed
+
+	names := func() []string {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		return names
+	}()
+	want := []string{"errlog.log", "errlog.log.20261018T110003.000.gz", "errlog.log.20261018T110005.000.gz", "errlog.log.bak"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+
+	f, err := os.Open(filepath.Join(dir, want[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"Message":"fourth"`) || !strings.Contains(string(b), `"Message":"fifth"`) {
+		t.Errorf("last backup = %s, want the fourth and fifth reports", b)
+	}
+}
+
+func TestRotatingFileSinkRotateError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "errlog.log")
+	oldest := path + ".20261017T100000.000" // a directory, which cannot be removed
+	if err := os.MkdirAll(filepath.Join(oldest, "keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	sink, err := NewRotatingFileSink(path, JSONRenderer{}, RotateConfig{MaxSize: 150, MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	sink.now = func() time.Time { return now }
+
+	if err := sink.Send(&Report{Message: "first", Level: LevelError}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(&Report{Message: "second", Level: LevelError}); err == nil { // the file is rotated, but the oldest backup cannot be removed
+		t.Fatal("Send succeeded with a backup which cannot be removed")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"Message":"second"`) {
+		t.Errorf("file = %s, want the report sent during the failed rotation", b)
+	}
+}
+
+func TestRotatingFileSinkReopen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "errlog.log")
+
+	sink, err := NewRotatingFileSink(path, JSONRenderer{}, RotateConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Rotate(); err == nil {
+		t.Fatal("Rotate succeeded without the directory of the file")
+	}
+
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(&Report{Message: "after rotation", Level: LevelError}); err != nil {
+		t.Fatalf("Send after a failed rotation: %s", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"Message":"after rotation"`) {
+		t.Errorf("file = %s, want the report sent after the failed rotation", b)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(&Report{Message: "closed"}); err != os.ErrClosed {
+		t.Errorf("Send after Close = %v, want os.ErrClosed", err)
+	}
+}
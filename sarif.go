@@ -0,0 +1,189 @@
+package errlog
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifRuleID  = "errlog/error"
+	sarifRootID  = "SRCROOT" //uriBaseId of paths relative to SARIFRenderer.BaseDir
+)
+
+//SARIFRenderer renders reports as a SARIF 2.1.0 log, so that CI systems (eg: GitHub code scanning) show them
+//as annotations on the failing lines. Each report is a result located at its failing call, with its stack trace as a code flow.
+type SARIFRenderer struct {
+	BaseDir     string       //Root of the repository, files under it are referenced relative to it (others by absolute URI)
+	StackFilter *StackFilter //Which frames of the stack trace to put in the code flow (nil puts them all)
+}
+
+//Render writes a SARIF log with r as only result
+func (s *SARIFRenderer) Render(w io.Writer, r *Report) error {
+	return s.RenderAll(w, []*Report{r})
+}
+
+//RenderAll writes a SARIF log with a result for each report
+func (s *SARIFRenderer) RenderAll(w io.Writer, reports []*Report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "errlog",
+			InformationURI: "https://github.com/snwfdhmp/errlog",
+			Rules: []sarifRule{{
+				ID:               sarifRuleID,
+				ShortDescription: sarifMessage{Text: "Error reported by errlog"},
+			}},
+		}},
+		Results: make([]sarifResult, 0, len(reports)),
+	}
+	if s.BaseDir != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			sarifRootID: {URI: "file://" + filepath.ToSlash(filepath.Clean(s.BaseDir)) + "/"},
+		}
+	}
+
+	for _, r := range reports {
+		run.Results = append(run.Results, s.result(r))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+//result converts r to a SARIF result, located at the failing line of its first frame
+func (s *SARIFRenderer) result(r *Report) sarifResult {
+	result := sarifResult{
+		RuleID:              sarifRuleID,
+		Level:               "error",
+		Message:             sarifMessage{Text: r.Message},
+		PartialFingerprints: map[string]string{"errlog/v1": r.Fingerprint},
+	}
+	if len(r.Stack) == 0 {
+		return result
+	}
+
+	location := s.location(r.Stack[0])
+	if ex, err := loadSourceExcerpt(r.Stack[0].SourcePathRef, r.Stack[0].SourceLineRef, 0, 0); err == nil && ex.FailingLine != -1 {
+		location.PhysicalLocation.Region = &sarifRegion{
+			StartLine:   ex.FailingLine + 1,
+			StartColumn: ex.ColumnStart + 1,
+			EndLine:     ex.FailingLine + 1,
+			EndColumn:   ex.ColumnEnd + 2, // SARIF end columns are exclusive
+		}
+	}
+	result.Locations = []sarifLocation{location}
+
+	//code flows are in execution order: outermost frame first
+	var flow sarifThreadFlow
+	frames := filterStack(r.Stack, s.StackFilter)
+	for i := len(frames) - 1; i >= 0; i-- {
+		if frames[i].collapsed > 0 {
+			continue
+		}
+		flow.Locations = append(flow.Locations, sarifThreadFlowLocation{Location: s.location(frames[i].item)})
+	}
+	if len(flow.Locations) > 0 {
+		result.CodeFlows = []sarifCodeFlow{{ThreadFlows: []sarifThreadFlow{flow}}}
+	}
+
+	return result
+}
+
+//location returns the SARIF location of the call site of item
+func (s *SARIFRenderer) location(item StackTraceItem) sarifLocation {
+	artifact := sarifArtifactLocation{URI: "file://" + filepath.ToSlash(item.SourcePathRef)}
+	if s.BaseDir != "" {
+		if rel, err := filepath.Rel(s.BaseDir, item.SourcePathRef); err == nil && !strings.HasPrefix(rel, "..") {
+			artifact = sarifArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: sarifRootID}
+		}
+	}
+
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: artifact,
+			Region:           &sarifRegion{StartLine: item.SourceLineRef},
+		},
+		Message: &sarifMessage{Text: formatCall(item)},
+	}
+}
+
+//SARIF 2.1.0 objects, limited to what errlog fills (see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	CodeFlows           []sarifCodeFlow   `json:"codeFlows,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifCodeFlow struct {
+	ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+}
+
+type sarifThreadFlow struct {
+	Locations []sarifThreadFlowLocation `json:"locations"`
+}
+
+type sarifThreadFlowLocation struct {
+	Location sarifLocation `json:"location"`
+}
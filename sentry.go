@@ -0,0 +1,208 @@
+package errlog
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSentryTimeout = 5 * time.Second
+)
+
+//defaultSentryClient posts events when SentrySink.Client is nil, without blocking Send for long if Sentry is unreachable
+var defaultSentryClient = &http.Client{Timeout: defaultSentryTimeout}
+
+//SentrySink sends reports as events to Sentry, or to any server accepting Sentry envelopes (eg: GlitchTip, Errbit).
+//Stack frames carry the source lines around their call site, in the range DebugSource would print.
+type SentrySink struct {
+	Client      *http.Client      //Client used to post events (nil means a client with a 5s timeout)
+	LinesBefore int               //How many lines of source code to send *before* the call site of each frame
+	LinesAfter  int               //How many lines of source code to send *after* the call site of each frame
+	Environment string            //Environment of the events (eg: production), optional
+	Release     string            //Release of the events (eg: a version or a commit), optional
+	Tags        map[string]string //Tags added to every event, optional
+	StackFilter *StackFilter      //Which frames are in app, the others are collapsed by Sentry (nil uses the main module)
+
+	endpoint string //URL of the envelope endpoint of the project
+	dsn      string
+	auth     string //X-Sentry-Auth header
+}
+
+//NewSentrySink returns a SentrySink posting to the project of dsn (eg: https://<key>@o1.ingest.sentry.io/<project>),
+//sending as many source lines as DefaultLogger prints
+func NewSentrySink(dsn string) (*SentrySink, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Sentry DSN: %s", err)
+	}
+	key := u.User.Username()
+	slash := strings.LastIndex(u.Path, "/")
+	if u.Scheme == "" || u.Host == "" || key == "" || slash == -1 || u.Path[slash+1:] == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN %q: want <scheme>://<key>@<host>/<project>", dsn)
+	}
+
+	cfg := DefaultLogger.Config()
+	return &SentrySink{
+		LinesBefore: cfg.LinesBefore,
+		LinesAfter:  cfg.LinesAfter,
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, u.Path[:slash], u.Path[slash+1:]),
+		dsn:         dsn,
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=errlog, sentry_key=%s", key),
+	}, nil
+}
+
+//Send posts r as a Sentry event
+func (s *SentrySink) Send(r *Report) error {
+	event := s.event(r)
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	if err := enc.Encode(map[string]string{"event_id": event.EventID, "dsn": s.dsn, "sent_at": time.Now().UTC().Format(time.RFC3339)}); err != nil {
+		return err
+	}
+	if err := enc.Encode(map[string]string{"type": "event"}); err != nil {
+		return err
+	}
+	if err := enc.Encode(event); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	client := s.Client
+	if client == nil {
+		client = defaultSentryClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sentry responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+//event converts r to a Sentry event
+func (s *SentrySink) event(r *Report) sentryEvent {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   r.Time.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       "error",
+		Logger:      "errlog",
+		Environment: s.Environment,
+		Release:     s.Release,
+		Fingerprint: []string{r.Fingerprint},
+		Tags:        s.Tags,
+	}
+	if len(r.Vars) > 0 {
+		event.Extra = make(map[string]string, len(r.Vars))
+		for _, v := range r.Vars {
+			event.Extra[v.Name] = v.Value
+		}
+	}
+
+	filter := s.StackFilter
+	if filter == nil {
+		filter = &StackFilter{}
+	}
+
+	//Sentry wants the outermost frame first
+	frames := make([]sentryFrame, 0, len(r.Stack))
+	for i := len(r.Stack) - 1; i >= 0; i-- {
+		frames = append(frames, s.frame(r.Stack[i], filter))
+	}
+
+	event.Exception.Values = []sentryException{{
+		Type:       r.ErrorType,
+		Value:      r.Message,
+		Stacktrace: sentryStacktrace{Frames: frames},
+	}}
+
+	return event
+}
+
+//frame converts item to a Sentry frame, with the source lines around its call site
+func (s *SentrySink) frame(item StackTraceItem, filter *StackFilter) sentryFrame {
+	pkg := packageOf(item.CallingObject)
+	frame := sentryFrame{
+		Function: strings.TrimPrefix(item.CallingObject, pkg+"."),
+		Module:   pkg,
+		Filename: shortSourcePath(item.SourcePathRef),
+		AbsPath:  item.SourcePathRef,
+		Lineno:   item.SourceLineRef,
+		InApp:    filter.isUserFrame(item),
+	}
+
+	ex, err := loadSourceExcerpt(item.SourcePathRef, item.SourceLineRef, max(s.LinesBefore, 0), max(s.LinesAfter, 0))
+	lineIndex := item.SourceLineRef - 1
+	if err != nil || lineIndex < 0 || lineIndex >= len(ex.Lines) {
+		return frame
+	}
+
+	frame.ContextLine = ex.Lines[lineIndex]
+	frame.PreContext = append([]string{}, ex.Lines[min(ex.StartLine, lineIndex):lineIndex]...)
+	frame.PostContext = append([]string{}, ex.Lines[lineIndex+1:max(ex.EndLine, lineIndex+1)]...)
+
+	return frame
+}
+
+//Sentry event payload, limited to what errlog fills (see https://develop.sentry.dev/sdk/data-model/event-payloads/)
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Fingerprint []string          `json:"fingerprint"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+}
+
+type sentryException struct {
+	Type       string           `json:"type"`
+	Value      string           `json:"value"`
+	Stacktrace sentryStacktrace `json:"stacktrace"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function    string   `json:"function"`
+	Module      string   `json:"module"`
+	Filename    string   `json:"filename"`
+	AbsPath     string   `json:"abs_path"`
+	Lineno      int      `json:"lineno"`
+	PreContext  []string `json:"pre_context,omitempty"`
+	ContextLine string   `json:"context_line,omitempty"`
+	PostContext []string `json:"post_context,omitempty"`
+	InApp       bool     `json:"in_app"`
+}
@@ -0,0 +1,69 @@
+package errlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSentrySink(t *testing.T) {
+	var (
+		path, auth string
+		event      sentryEvent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("X-Sentry-Auth")
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+		for i := 0; scanner.Scan(); i++ {
+			if i == 2 { // envelope header, item header, then the event
+				json.Unmarshal(scanner.Bytes(), &event)
+			}
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewSentrySink(strings.Replace(server.URL, "://", "://public@", 1) + "/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Client = server.Client()
+	sink.LinesBefore, sink.LinesAfter = 2, 1
+
+	l := NewLogger(&Config{PrintFunc: func(format string, data ...interface{}) {}, Sink: sink})
+	err = errors.New("send me")
+	l.Debug(err)
+
+	if path != "/api/42/envelope/" || !strings.Contains(auth, "sentry_key=public") {
+		t.Errorf("posted to %s with auth %q", path, auth)
+	}
+	if len(event.Exception.Values) != 1 {
+		t.Fatalf("event has %d exceptions, want 1", len(event.Exception.Values))
+	}
+	exception := event.Exception.Values[0]
+	if exception.Value != "send me" || len(event.Fingerprint) != 1 || event.Fingerprint[0] == "" {
+		t.Errorf("unexpected exception %+v with fingerprint %v", exception, event.Fingerprint)
+	}
+
+	frames := exception.Stacktrace.Frames
+	top := frames[len(frames)-1]
+	want := sentryFrame{
+		Function:    "TestSentrySink",
+		Module:      "github.com/snwfdhmp/errlog",
+		Filename:    top.Filename,
+		AbsPath:     top.AbsPath,
+		Lineno:      top.Lineno,
+		PreContext:  []string{`	err = errors.New("send me")`},
+		ContextLine: "	l.Debug(err)",
+		PostContext: []string{""},
+		InApp:       true,
+	}
+	if !reflect.DeepEqual(top, want) {
+		t.Errorf("top frame = %#v, want %#v", top, want)
+	}
+}
@@ -0,0 +1,237 @@
+package errlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//Sink receives the reports of a logger, to store them or send them elsewhere (see Config.Sink)
+type Sink interface {
+	Send(r *Report) error
+}
+
+//Level is the severity of a report, used by FanOut to filter reports per sink
+type Level int
+
+//Levels of reports, from the least to the most severe. 0 is no level.
+const (
+	LevelDebug Level = iota + 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+//String returns the name of the level (eg: error)
+func (lvl Level) String() string {
+	if name, ok := levelNames[lvl]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(lvl))
+}
+
+//MarshalText encodes the level as its name
+func (lvl Level) MarshalText() ([]byte, error) {
+	return []byte(lvl.String()), nil
+}
+
+//UnmarshalText decodes a level from its name
+func (lvl *Level) UnmarshalText(text []byte) error {
+	for l, name := range levelNames {
+		if strings.EqualFold(string(text), name) {
+			*lvl = l
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %q", text)
+}
+
+//Output is a sink of a FanOut, with its own level filter
+type Output struct {
+	Sink     Sink
+	MinLevel Level //Reports below this level are not sent to Sink (0 sends them all)
+}
+
+//FanOut is a Sink sending each report to several sinks
+type FanOut struct {
+	Outputs []Output
+}
+
+//Send sends r to every output accepting its level. It returns the errors of all the sinks.
+func (f *FanOut) Send(r *Report) error {
+	var errs []error
+	for _, o := range f.Outputs {
+		if r.Level < o.MinLevel {
+			continue
+		}
+		if err := o.Sink.Send(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+//NewFanOutLogger returns a logger sending its reports to outputs instead of printing them, eg: to print errors
+//to the terminal while keeping every report in a JSON lines file:
+//
+//	file, err := errlog.NewJSONLFileSink("errlog.jsonl")
+//	...
+//	l := errlog.NewFanOutLogger(&errlog.Config{Level: errlog.LevelWarn},
+//		errlog.Output{Sink: errlog.NewTerminalSink(), MinLevel: errlog.LevelError},
+//		errlog.Output{Sink: file},
+//	)
+//
+//cfg.Sink is replaced. cfg.PrintFunc only prints the errors of errlog itself (eg: a sink failing).
+func NewFanOutLogger(cfg *Config, outputs ...Output) Logger {
+	cfg.Sink = &FanOut{Outputs: outputs}
+	l := NewLogger(cfg).(*logger)
+	l.sinksOnly = true
+	return l
+}
+
+//TextRenderer renders reports like a logger prints them
+type TextRenderer struct {
+	config Config
+	plain  bool
+}
+
+//NewTextRenderer returns a TextRenderer printing what cfg prints (error, source, stack...).
+//If plain is true, colors are removed (eg: for files).
+func NewTextRenderer(cfg *Config, plain bool) *TextRenderer {
+	return &TextRenderer{config: *cfg, plain: plain}
+}
+
+var regexpColor = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+//Render writes r as text
+func (t *TextRenderer) Render(w io.Writer, r *Report) error {
+	if len(r.Stack) == 0 {
+		_, err := fmt.Fprintf(w, "Error: %s\n", r.Message)
+		return err
+	}
+
+	var b strings.Builder
+	cfg := t.config
+	cfg.PrintFunc = func(format string, data ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", data...)
+	}
+	l := newLogger(&cfg)
+	l.printReport(r)
+
+	text := b.String()
+	if t.plain {
+		text = regexpColor.ReplaceAllString(text, "")
+	}
+	_, err := io.WriteString(w, text)
+	return err
+}
+
+//JSONRenderer renders reports as JSON, one per line
+type JSONRenderer struct{}
+
+//Render writes r as a line of JSON
+func (JSONRenderer) Render(w io.Writer, r *Report) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+//WriterSink renders reports to a writer. Reports are written whole, even with concurrent Debug calls.
+type WriterSink struct {
+	mu       sync.Mutex
+	w        io.Writer
+	renderer Renderer
+}
+
+//NewWriterSink returns a WriterSink rendering reports to w with renderer
+func NewWriterSink(w io.Writer, renderer Renderer) *WriterSink {
+	return &WriterSink{w: w, renderer: renderer}
+}
+
+//NewTerminalSink returns a WriterSink printing reports to stdout like DefaultLogger
+func NewTerminalSink() *WriterSink {
+	return NewWriterSink(os.Stdout, NewTextRenderer(DefaultLogger.Config(), false))
+}
+
+//Send renders r to the writer
+func (s *WriterSink) Send(r *Report) error {
+	var b strings.Builder
+	if err := s.renderer.Render(&b, r); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+//FileSink is a WriterSink appending to a file
+type FileSink struct {
+	*WriterSink
+	file *os.File
+}
+
+//NewFileSink returns a FileSink appending reports rendered with renderer to the file at path, created if needed
+func NewFileSink(path string, renderer Renderer) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{WriterSink: NewWriterSink(file, renderer), file: file}, nil
+}
+
+//NewJSONLFileSink returns a FileSink appending reports to the file at path as JSON lines, for later analysis
+func NewJSONLFileSink(path string) (*FileSink, error) {
+	return NewFileSink(path, JSONRenderer{})
+}
+
+//Close closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+//RingBufferSink keeps the last reports in memory (eg: to show them on a debug page or in tests)
+type RingBufferSink struct {
+	mu      sync.Mutex
+	reports []*Report
+	next    int //index of the next report to replace once full
+}
+
+//NewRingBufferSink returns a RingBufferSink keeping the last size reports
+func NewRingBufferSink(size int) *RingBufferSink {
+	return &RingBufferSink{reports: make([]*Report, 0, max(size, 1))}
+}
+
+//Send keeps r, forgetting the oldest report if the buffer is full
+func (s *RingBufferSink) Send(r *Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.reports) < cap(s.reports) {
+		s.reports = append(s.reports, r)
+		return nil
+	}
+	s.reports[s.next] = r
+	s.next = (s.next + 1) % len(s.reports)
+	return nil
+}
+
+//Reports returns the kept reports, oldest first
+func (s *RingBufferSink) Reports() []*Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append(append([]*Report(nil), s.reports[s.next:]...), s.reports[:s.next]...)
+}
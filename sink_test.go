@@ -0,0 +1,88 @@
+package errlog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFanOutLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errlog.jsonl")
+	file, err := NewJSONLFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errorSink := NewRingBufferSink(10)
+
+	var printed strings.Builder
+	l := NewFanOutLogger(&Config{
+		PrintFunc: func(format string, data ...interface{}) { printed.WriteString(format) },
+		Level:     LevelWarn,
+	},
+		Output{Sink: errorSink, MinLevel: LevelError},
+		Output{Sink: file},
+	)
+	l.Debug(errors.New("slow query"))
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if printed.Len() > 0 {
+		t.Errorf("fan-out logger printed %q", printed.String())
+	}
+	if got := len(errorSink.Reports()); got != 0 {
+		t.Errorf("error sink got %d warnings, want 0", got)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var line struct {
+		Message string
+		Level   Level
+		Stack   []StackTraceItem
+	}
+	if err := json.Unmarshal(b, &line); err != nil {
+		t.Fatalf("%s in %q", err, b)
+	}
+	if line.Message != "slow query" || line.Level != LevelWarn || len(line.Stack) == 0 || line.Stack[0].CallingObject != "github.com/snwfdhmp/errlog.TestFanOutLogger" {
+		t.Errorf("unexpected JSON line %s", b)
+	}
+}
+
+func TestRingBufferSink(t *testing.T) {
+	ring := NewRingBufferSink(2)
+	for _, msg := range []string{"a", "b", "c"} {
+		ring.Send(&Report{Message: msg})
+	}
+
+	var got []string
+	for _, r := range ring.Reports() {
+		got = append(got, r.Message)
+	}
+	if strings.Join(got, ",") != "b,c" {
+		t.Errorf("Reports() = %v, want [b c]", got)
+	}
+}
+
+func TestFanOutLoggerRateLimit(t *testing.T) {
+	sink := NewRingBufferSink(10)
+	l := NewFanOutLogger(&Config{
+		PrintFunc: func(format string, data ...interface{}) {},
+		RateLimit: &RateLimitConfig{Window: time.Hour, Burst: 1},
+	}, Output{Sink: sink}).(*logger)
+	defer l.Close()
+
+	for i := 0; i < 3; i++ {
+		l.Debug(errors.New("connection refused"))
+	}
+
+	if got := len(sink.Reports()); got != 1 {
+		t.Errorf("sink got %d reports of the same error, want 1", got)
+	}
+}
@@ -0,0 +1,116 @@
+package errlog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+//SourceExcerpt holds the lines of source code surrounding a Debug call, as printed by DebugSource.
+//Line fields are indexes in Lines (line number - 1).
+type SourceExcerpt struct {
+	File        string   //Path of the source file
+	Lines       []string //Lines of the file, up to EndLine
+	DebugLine   int      //Line number of the Debug call, as found in the stack trace
+	FuncLine    int      //Index of the line declaring the enclosing func (-1 if not found)
+	StartLine   int      //Index of the first line of the excerpt
+	EndLine     int      //Index of the line after the last line of the excerpt
+	FailingLine int      //Index of the line defining or returning the debugged error (-1 if not found)
+	ColumnStart int      //Column where the failing call starts on FailingLine
+	ColumnEnd   int      //Column where the failing call ends on FailingLine
+}
+
+//ExcerptLine is a line of a SourceExcerpt, ready to be rendered
+type ExcerptLine struct {
+	Number  int    //Line number in the file, 0 for the "..." separator
+	Text    string //Source code of the line
+	Func    bool   //Whether this line declares the enclosing func
+	Failing bool   //Whether this line defines the debugged error
+}
+
+//loadSourceExcerpt reads filepath and finds the lines to print around debugLineNumber
+func loadSourceExcerpt(filepath string, debugLineNumber, linesBefore, linesAfter int) (*SourceExcerpt, error) {
+	b, err := afero.ReadFile(fs, filepath)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(b), "\n")
+	if debugLineNumber < 1 || debugLineNumber > len(lines) { // eg: stale stack trace, or sources of another version
+		return nil, fmt.Errorf("line %d is out of %s (%d lines)", debugLineNumber, filepath, len(lines))
+	}
+
+	// set line range to print based on config values and debugLineNumber
+	minLine := debugLineNumber - linesBefore
+	maxLine := debugLineNumber + linesAfter
+
+	//delete blank lines from range and clean range if out of lines range
+	deleteBlankLinesFromRange(lines, &minLine, &maxLine)
+
+	//find func line and adjust minLine if below
+	funcLine := findFuncLine(lines, debugLineNumber)
+	if funcLine > minLine {
+		minLine = funcLine + 1
+	}
+
+	//try to find failing line if any (before trimming lines, as the Debug call may span lines after maxLine)
+	failingLineIndex, columnStart, columnEnd := findFailingLine(lines, funcLine, debugLineNumber)
+
+	//free some memory from unused values
+	lines = lines[:maxLine+1]
+
+	return &SourceExcerpt{
+		File:        filepath,
+		Lines:       lines,
+		DebugLine:   debugLineNumber,
+		FuncLine:    funcLine,
+		StartLine:   minLine,
+		EndLine:     maxLine,
+		FailingLine: failingLineIndex,
+		ColumnStart: columnStart,
+		ColumnEnd:   columnEnd,
+	}, nil
+}
+
+//printSourceOptions returns the options for printing the excerpt with (*logger).PrintSource
+func (ex *SourceExcerpt) printSourceOptions() PrintSourceOptions {
+	return PrintSourceOptions{
+		FuncLine: ex.FuncLine,
+		Highlighted: map[int][]int{
+			ex.FailingLine: {ex.ColumnStart, ex.ColumnEnd},
+		},
+		StartLine: ex.StartLine,
+		EndLine:   ex.EndLine,
+	}
+}
+
+//Excerpt returns the lines to render, in the same order as PrintSource prints them
+func (ex *SourceExcerpt) Excerpt() []ExcerptLine {
+	var excerpt []ExcerptLine
+
+	if ex.FuncLine != -1 && ex.FuncLine < ex.StartLine {
+		excerpt = append(excerpt, ExcerptLine{Number: ex.FuncLine + 1, Text: ex.Lines[ex.FuncLine], Func: true})
+		if ex.FuncLine < ex.StartLine-1 {
+			excerpt = append(excerpt, ExcerptLine{Text: "..."})
+		}
+	}
+
+	for i := ex.StartLine; i < ex.EndLine; i++ {
+		excerpt = append(excerpt, ExcerptLine{
+			Number:  i + 1,
+			Text:    ex.Lines[i],
+			Func:    i == ex.FuncLine,
+			Failing: i == ex.FailingLine,
+		})
+	}
+
+	return excerpt
+}
+
+//shortSourcePath removes $GOPATH/src/ from filepath
+func shortSourcePath(filepath string) string {
+	if gopath == "" {
+		return filepath
+	}
+	return strings.Replace(filepath, gopath+"/src/", "", -1)
+}
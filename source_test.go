@@ -0,0 +1,98 @@
+package errlog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/spf13/afero"
+)
+
+func TestLoadSourceExcerptLineOutOfFile(t *testing.T) {
+	defer func(osFs afero.Fs) { fs = osFs }(fs)
+	fs = afero.NewMemMapFs()
+	source := "package main\n\nfunc main() {\n\terrlog.Debug(err)\n}" // no trailing newline
+	if err := afero.WriteFile(fs, "/src/main.go", []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []int{-1, 0, 6, 40} {
+		if _, err := loadSourceExcerpt("/src/main.go", line, 4, 2); err == nil {
+			t.Errorf("loadSourceExcerpt(line %d) of a 5 lines file returned no error", line)
+		}
+	}
+
+	for _, line := range []int{1, 4, 5} {
+		if _, err := loadSourceExcerpt("/src/main.go", line, 4, 2); err != nil {
+			t.Errorf("loadSourceExcerpt(line %d): %s", line, err)
+		}
+	}
+}
+
+func TestFindCallColumns(t *testing.T) {
+	cases := []struct {
+		line, callee string
+		start, end   int
+		ok           bool
+	}{
+		{"\tif err := store.Save(ctx, id); err != nil {", "main.(*Store).Save", 17, 29, true},
+		{"\tgo func() { work() }()", "main.main.func1", 1, 22, true},
+		{"\t\t  ", "main.work", 0, 0, false},
+		{"", "main.work", 0, 0, false},
+	}
+	for _, c := range cases {
+		start, end, ok := findCallColumns(c.line, c.callee)
+		if start != c.start || end != c.end || ok != c.ok {
+			t.Errorf("findCallColumns(%q, %q) = %d, %d, %t, want %d, %d, %t", c.line, c.callee, start, end, ok, c.start, c.end, c.ok)
+		}
+	}
+}
+
+func TestPrintSourceHighlightOutOfLine(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+
+	var printed []string
+	l := NewLogger(&Config{PrintFunc: func(format string, data ...interface{}) {
+		printed = append(printed, fmt.Sprintf(format, data...))
+	}}).(*logger)
+
+	lines := []string{"func main() {", "\tshort()", "", "}", "", "", "", "", "", ""}
+	l.PrintSource(lines, PrintSourceOptions{
+		FuncLine:    -1,
+		StartLine:   1,
+		EndLine:     3,
+		Highlighted: map[int][]int{1: {1, 9}, 2: {4, -1}},
+	})
+
+	if want := []string{"2: \tshort()", "3: "}; strings.Join(printed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("PrintSource() printed %q, want %q", printed, want)
+	}
+}
+
+func TestFindFailingLineDebugCalls(t *testing.T) {
+	cases := []struct {
+		call    string
+		failing int //index of the failing line, -1 if not found
+	}{
+		{"\terrlog.Debug(err)", 1},
+		{"\terrlog.Debug(fmt.Errorf(\"wrap: %w\", err))", -1},
+		{"\terrlog.DebugVars(err, \"path\", path)", 1},
+		{"\terrlog.DebugVars(fmt.Errorf(\"open (%s\", path), \"path\", path)", -1},
+		{"\terrlog.DebugContext(ctx, err)", 1},
+		{"\terrlog.DebugContext(context.WithValue(ctx, key, \"a,b\"), err)", 1},
+		{"\terrlog.DebugContext(ctx, errors.Join(err, io.EOF))", -1},
+		{"\terrlog.Debug(err[0])", -1},
+		{"\treturn fmt.Errorf(\"sync: %w\", err)", 2},
+		{"\treturn errlog.Debug(err)", 1},
+		{"\tgo run(func() error { return errors.New(\"failed\") })", 2},
+	}
+	for _, c := range cases {
+		lines := []string{"func load(path string) {", "\terr := open(path)", c.call, "}"}
+		failing, _, _ := findFailingLine(lines, 0, 3)
+		if failing != c.failing {
+			t.Errorf("findFailingLine(%q) = %d, want %d", c.call, failing, c.failing)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package errlog
+
+import (
+	"path"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+//StackFilter holds the rules deciding which frames are printed in stack traces (see Config.StackFilter)
+//
+//Package patterns are either path.Match globs (eg: github.com/me/*) or end with "/..." to match a package
+//and all its subpackages (eg: github.com/me/project/...).
+type StackFilter struct {
+	HideRuntime        bool     //Hide frames of the Go runtime (runtime, runtime/debug, ...)
+	HideStdlib         bool     //Hide frames of the standard library (including the runtime)
+	CollapseThirdParty bool     //Replace consecutive frames of a third-party module by "... N frames in <module>"
+	Include            []string //Packages always printed, whatever the other rules say
+	Exclude            []string //Packages never printed
+	ModulePath         string   //Path of the module of your code, never considered as third-party (defaults to the main module of the binary)
+}
+
+//stackFrame is a frame to print, or a group of collapsed frames if collapsed > 0
+type stackFrame struct {
+	item      StackTraceItem
+	collapsed int    //how many frames are collapsed in this one
+	module    string //module of the collapsed frames
+}
+
+var (
+	mainModulePath     string
+	buildModulePaths   []string //paths of the main module and of the dependencies of the binary
+	mainModulePathOnce sync.Once
+)
+
+//mainModule returns the path of the main module of the binary, if any
+func mainModule() string {
+	readBuildModules()
+	return mainModulePath
+}
+
+//readBuildModules reads the paths of the modules of the binary once
+func readBuildModules() {
+	mainModulePathOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		mainModulePath = info.Main.Path
+		if info.Main.Path != "" {
+			buildModulePaths = append(buildModulePaths, info.Main.Path)
+		}
+		for _, dep := range info.Deps {
+			buildModulePaths = append(buildModulePaths, dep.Path)
+		}
+	})
+}
+
+//filterStack applies filter to stLines. A nil filter keeps every frame.
+func filterStack(stLines []StackTraceItem, filter *StackFilter) []stackFrame {
+	frames := make([]stackFrame, 0, len(stLines))
+	for _, item := range stLines {
+		if filter == nil {
+			frames = append(frames, stackFrame{item: item})
+			continue
+		}
+
+		pkg := packageOf(item.CallingObject)
+		if !filter.keeps(pkg) {
+			continue
+		}
+
+		if filter.CollapseThirdParty && !matchAnyPackage(filter.Include, pkg) && filter.isThirdParty(pkg) {
+			module := moduleRoot(pkg)
+			if last := len(frames) - 1; last >= 0 && frames[last].collapsed > 0 && frames[last].module == module {
+				frames[last].collapsed++
+				continue
+			}
+			frames = append(frames, stackFrame{item: item, collapsed: 1, module: module})
+			continue
+		}
+
+		frames = append(frames, stackFrame{item: item})
+	}
+
+	//a single frame is printed rather than collapsed
+	for i := range frames {
+		if frames[i].collapsed == 1 {
+			frames[i].collapsed = 0
+		}
+	}
+
+	return frames
+}
+
+//keeps tells whether frames of pkg should be printed according to Include, Exclude, HideRuntime and HideStdlib
+func (f *StackFilter) keeps(pkg string) bool {
+	if matchAnyPackage(f.Include, pkg) {
+		return true
+	}
+	if matchAnyPackage(f.Exclude, pkg) {
+		return false
+	}
+	if f.HideRuntime && isRuntimePackage(pkg) {
+		return false
+	}
+	if f.HideStdlib && f.isStdlib(pkg) {
+		return false
+	}
+	return true
+}
+
+//isStdlib tells whether pkg is part of the standard library, and not of ModulePath (which may have no dot, eg: myapp)
+func (f *StackFilter) isStdlib(pkg string) bool {
+	return isStdlibPackage(pkg) && (f.ModulePath == "" || !inModule(pkg, f.ModulePath))
+}
+
+//isThirdParty tells whether pkg is neither in the standard library nor in the module of the user
+func (f *StackFilter) isThirdParty(pkg string) bool {
+	if f.isStdlib(pkg) || pkg == "main" {
+		return false
+	}
+
+	module := f.ModulePath
+	if module == "" {
+		module = mainModule()
+	}
+	return module == "" || !inModule(pkg, module)
+}
+
+//isUserFrame tells whether item is a frame of the code of the user: not in the standard library and not third-party.
+//When the module of the user is unknown, frames whose source is in the module cache or in a vendor directory are third-party.
+func (f *StackFilter) isUserFrame(item StackTraceItem) bool {
+	pkg := packageOf(item.CallingObject)
+	if matchAnyPackage(f.Include, pkg) {
+		return true
+	}
+	if matchAnyPackage(f.Exclude, pkg) || f.isStdlib(pkg) {
+		return false
+	}
+	if f.ModulePath == "" && mainModule() == "" {
+		return !strings.Contains(item.SourcePathRef, "/pkg/mod/") && !strings.Contains(item.SourcePathRef, "/vendor/")
+	}
+	return !f.isThirdParty(pkg)
+}
+
+//regexpMajorVersion matches the major version ending the last element of gopkg.in paths (eg: .v3 in gopkg.in/yaml.v3)
+var regexpMajorVersion = regexp.MustCompile(`^\.v[0-9]+(?:\.|$)`)
+
+//packageOf returns the import path of the package of a function name as printed in stack traces (eg: github.com/me/project
+//for github.com/me/project.(*T).Method, gopkg.in/yaml.v3 for gopkg.in/yaml%2ev3.Unmarshal)
+func packageOf(function string) string {
+	pkg, _ := splitPackage(function)
+	return pkg
+}
+
+//splitPackage splits a function name as printed in stack traces into the import path of its package and the rest of
+//the name (eg: github.com/me/project and (*T).Method). The runtime escapes the dots of the last element of the path,
+//which are also recognized unescaped when they end with a major version (eg: gopkg.in/yaml.v3.Unmarshal).
+func splitPackage(function string) (pkg, name string) {
+	lastSlash := strings.LastIndex(function, "/")
+	end := strings.Index(function[lastSlash+1:], ".")
+	if end == -1 {
+		return strings.ReplaceAll(function, "%2e", "."), ""
+	}
+	end += lastSlash + 1
+	if lastSlash >= 0 {
+		end += len(strings.TrimSuffix(regexpMajorVersion.FindString(function[end:]), "."))
+	}
+	if end < len(function) {
+		name = function[end+1:]
+	}
+	return strings.ReplaceAll(function[:end], "%2e", "."), name
+}
+
+//isRuntimePackage tells whether pkg is part of the Go runtime
+func isRuntimePackage(pkg string) bool {
+	return pkg == "runtime" || strings.HasPrefix(pkg, "runtime/") || strings.HasPrefix(pkg, "internal/")
+}
+
+//isStdlibPackage tells whether pkg is part of the standard library: its first path element has no dot, and it is not
+//part of a module of the binary (module paths may have no dot, eg: myapp)
+func isStdlibPackage(pkg string) bool {
+	if pkg == "main" {
+		return false
+	}
+	first := strings.SplitN(pkg, "/", 2)[0]
+	if strings.Contains(first, ".") {
+		return false
+	}
+
+	readBuildModules()
+	for _, module := range buildModulePaths {
+		if inModule(pkg, module) {
+			return false
+		}
+	}
+	return true
+}
+
+//inModule tells whether pkg is a package of module
+func inModule(pkg, module string) bool {
+	return pkg == module || strings.HasPrefix(pkg, module+"/")
+}
+
+//moduleRoot guesses the module of a third-party package from its import path (eg: github.com/x/y for github.com/x/y/z)
+func moduleRoot(pkg string) string {
+	elems := strings.Split(pkg, "/")
+	if len(elems) > 3 {
+		elems = elems[:3]
+	}
+	return strings.Join(elems, "/")
+}
+
+//matchAnyPackage tells whether pkg matches one of patterns
+func matchAnyPackage(patterns []string, pkg string) bool {
+	for _, pattern := range patterns {
+		if matchPackage(pattern, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+//matchPackage tells whether pkg matches pattern, which is either a path.Match glob or ends with "/..."
+func matchPackage(pattern, pkg string) bool {
+	if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+		return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+	}
+	matched, err := path.Match(pattern, pkg)
+	return err == nil && matched
+}
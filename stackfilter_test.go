@@ -0,0 +1,131 @@
+package errlog
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFilterStack(t *testing.T) {
+	stLines := []StackTraceItem{
+		{CallingObject: "github.com/me/project/api.(*Server).handle"},
+		{CallingObject: "github.com/gin-gonic/gin.(*Context).Next"},
+		{CallingObject: "github.com/gin-gonic/gin/render.(*Engine).handle"},
+		{CallingObject: "github.com/gin-gonic/gin.(*Engine).ServeHTTP"},
+		{CallingObject: "net/http.serverHandler.ServeHTTP"},
+		{CallingObject: "net/http.(*conn).serve"},
+		{CallingObject: "github.com/me/project/vendored.Serve"},
+		{CallingObject: "main.main"},
+		{CallingObject: "runtime.goexit"},
+	}
+
+	cases := []struct {
+		name   string
+		filter *StackFilter
+		want   []string
+	}{
+		{
+			name:   "nil filter",
+			filter: nil,
+			want: []string{
+				"github.com/me/project/api.(*Server).handle",
+				"github.com/gin-gonic/gin.(*Context).Next",
+				"github.com/gin-gonic/gin/render.(*Engine).handle",
+				"github.com/gin-gonic/gin.(*Engine).ServeHTTP",
+				"net/http.serverHandler.ServeHTTP",
+				"net/http.(*conn).serve",
+				"github.com/me/project/vendored.Serve",
+				"main.main",
+				"runtime.goexit",
+			},
+		},
+		{
+			name:   "hide runtime",
+			filter: &StackFilter{HideRuntime: true},
+			want: []string{
+				"github.com/me/project/api.(*Server).handle",
+				"github.com/gin-gonic/gin.(*Context).Next",
+				"github.com/gin-gonic/gin/render.(*Engine).handle",
+				"github.com/gin-gonic/gin.(*Engine).ServeHTTP",
+				"net/http.serverHandler.ServeHTTP",
+				"net/http.(*conn).serve",
+				"github.com/me/project/vendored.Serve",
+				"main.main",
+			},
+		},
+		{
+			name:   "hide stdlib and collapse third-party",
+			filter: &StackFilter{HideStdlib: true, CollapseThirdParty: true, ModulePath: "github.com/me/project"},
+			want: []string{
+				"github.com/me/project/api.(*Server).handle",
+				"... 3 frames in github.com/gin-gonic/gin",
+				"github.com/me/project/vendored.Serve",
+				"main.main",
+			},
+		},
+		{
+			name:   "include and exclude",
+			filter: &StackFilter{HideStdlib: true, Include: []string{"net/http"}, Exclude: []string{"github.com/me/project/vendored", "github.com/gin-gonic/*"}},
+			want: []string{
+				"github.com/me/project/api.(*Server).handle",
+				"github.com/gin-gonic/gin/render.(*Engine).handle",
+				"net/http.serverHandler.ServeHTTP",
+				"net/http.(*conn).serve",
+				"main.main",
+			},
+		},
+		{
+			name:   "exclude subpackages",
+			filter: &StackFilter{Exclude: []string{"github.com/gin-gonic/gin/...", "github.com/me/project/..."}},
+			want: []string{
+				"net/http.serverHandler.ServeHTTP",
+				"net/http.(*conn).serve",
+				"main.main",
+				"runtime.goexit",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got []string
+			for _, frame := range filterStack(stLines, c.filter) {
+				if frame.collapsed > 0 {
+					got = append(got, fmt.Sprintf("... %d frames in %s", frame.collapsed, frame.module))
+					continue
+				}
+				got = append(got, frame.item.CallingObject)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("filterStack() =\n%q\nwant\n%q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestPackageOf(t *testing.T) {
+	for name, want := range map[string]string{
+		"main.main": "main",
+		"github.com/acme/billing.(*Invoice).Send":    "github.com/acme/billing",
+		"gopkg.in/yaml%2ev3.Unmarshal":               "gopkg.in/yaml.v3",
+		"gopkg.in/yaml.v3.(*Decoder).Decode":         "gopkg.in/yaml.v3",
+		"github.com/acme/billing.Process[...].func1": "github.com/acme/billing",
+		"myapp/api.(*Server).handle":                 "myapp/api",
+	} {
+		if got := packageOf(name); got != want {
+			t.Errorf("packageOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestStackFilterDotlessModule(t *testing.T) {
+	filter := &StackFilter{HideStdlib: true, CollapseThirdParty: true, ModulePath: "myapp"}
+	for pkg, stdlib := range map[string]bool{"myapp": false, "myapp/api": false, "net/http": true, "myapplication": true} {
+		if got := filter.isStdlib(pkg); got != stdlib {
+			t.Errorf("isStdlib(%q) = %t, want %t", pkg, got, stdlib)
+		}
+	}
+	if item := (StackTraceItem{CallingObject: "myapp/api.(*Server).handle"}); !filter.isUserFrame(item) {
+		t.Errorf("%s is not a user frame", item.CallingObject)
+	}
+}
@@ -0,0 +1,126 @@
+package errlog
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/afero"
+)
+
+const (
+	defaultStackSourceLines = 2
+)
+
+//printStackSource prints the stack trace like printStack, with the source code around the call site of the
+//top Config.StackSourceDepth user frames (see StackFilter for what a user frame is)
+func (l *logger) printStackSource(stLines []StackTraceItem) {
+	filter := l.Config().StackFilter
+	if filter == nil {
+		filter = &StackFilter{}
+	}
+	frames := filterStack(stLines, filter)
+
+	//find the frames to print source for, starting from the innermost one
+	withSource := make(map[int]bool)
+	for i := range frames {
+		if l.Config().StackSourceDepth > 0 && len(withSource) >= l.Config().StackSourceDepth {
+			break
+		}
+		if frames[i].collapsed == 0 && filter.isUserFrame(frames[i].item) {
+			withSource[i] = true
+		}
+	}
+
+	for i := len(frames) - 1; i >= 0; i-- {
+		if frames[i].collapsed > 0 {
+			l.Printf("... %d frames in %s", frames[i].collapsed, frames[i].module)
+			continue
+		}
+
+		item := frames[i].item
+		l.Printf("%s (%s:%d)%s", formatCall(item), shortSourcePath(item.SourcePathRef), item.SourceLineRef, inlinedMark(item))
+		if !withSource[i] {
+			continue
+		}
+
+		callee := ""
+		if i > 0 {
+			callee = frames[i-1].item.CallingObject
+		}
+		l.printCallSite(item, callee)
+	}
+}
+
+//printCallSite prints the lines around the call site of item, highlighting the call to callee
+func (l *logger) printCallSite(item StackTraceItem, callee string) {
+	b, err := afero.ReadFile(fs, item.SourcePathRef)
+	if err != nil {
+		l.Printf("%s", color.YellowString("    (source not available: %s)", err))
+		return
+	}
+	lines := strings.Split(string(b), "\n")
+
+	lineIndex := item.SourceLineRef - 1
+	if lineIndex < 0 || lineIndex >= len(lines) {
+		return
+	}
+
+	contextLines := l.Config().StackSourceLines
+	if contextLines < 1 {
+		contextLines = defaultStackSourceLines
+	}
+	start, end := lineIndex-contextLines, lineIndex+contextLines
+	deleteBlankLinesFromRange(lines, &start, &end)
+
+	highlighted := map[int][]int{}
+	if columnStart, columnEnd, ok := findCallColumns(lines[lineIndex], callee); ok {
+		highlighted[lineIndex] = []int{columnStart, columnEnd}
+	}
+	l.PrintSource(lines, PrintSourceOptions{
+		FuncLine:    -1,
+		Highlighted: highlighted,
+		StartLine:   start,
+		EndLine:     end + 1,
+	})
+}
+
+//findCallColumns returns the columns of the call to callee on line, or of the whole line (without indentation)
+//if the call is not found (eg: callee is a func literal). ok is false if line is blank.
+func findCallColumns(line, callee string) (columnStart, columnEnd int, ok bool) {
+	if strings.TrimSpace(line) == "" {
+		return 0, 0, false
+	}
+	columnStart = len(line) - len(strings.TrimLeft(line, " \t"))
+	columnEnd = len(strings.TrimRight(line, " \t")) - 1
+	ok = true
+
+	callee = regexpFingerprintGeneric.ReplaceAllString(callee, "")
+	name := callee[strings.LastIndex(callee, ".")+1:]
+	if name == "" || strings.HasPrefix(name, "func") {
+		return
+	}
+
+	index := strings.Index(line, name+"(")
+	if index == -1 {
+		index = strings.Index(line, name+"[") //generic funcs with explicit type arguments
+	}
+	if index == -1 {
+		return
+	}
+
+	//walk to the bracket closing the call
+	opened := 0
+	for j := index + len(name); j < len(line); j++ {
+		switch line[j] {
+		case '(':
+			opened++
+		case ')':
+			opened--
+			if opened == 0 {
+				return index, j, true
+			}
+		}
+	}
+
+	return index, columnEnd, true
+}
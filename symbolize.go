@@ -0,0 +1,171 @@
+package errlog
+
+import (
+	"bufio"
+	"debug/elf"
+	"debug/gosym"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	regexpSymbolizePC         = regexp.MustCompile(`^(?:pc=)?0x([0-9a-f]+)$`)                    // 0x45a1b2
+	regexpSymbolizeFuncOffset = regexp.MustCompile(`^([^\s]+?)(?:\(.*\))?\+0x([0-9a-f]+)$`)      // main.main+0x1a
+	regexpSymbolizeFileLine   = regexp.MustCompile(`^\t.*[\s]\+0x([0-9a-f]+)(?:[\s].*)?$`)       // "\t/path/file.go:12 +0x1a"
+	regexpSymbolizeFuncLine   = regexp.MustCompile(`^((?:[^\s()]|\(\*?[^\s()]+\))+)\((?:.*)\)$`) // main.(*T).Method(0x1, ...)
+)
+
+//Symbolizer resolves program counters of a Go binary to functions and source lines, using its pclntab.
+//It lets you get source excerpts for crash reports which only contain addresses, as long as you still have the binary.
+//Inlined frames are not expanded: a PC resolves to the outermost function.
+type Symbolizer struct {
+	table *gosym.Table
+}
+
+//NewSymbolizer reads the symbol table of the ELF Go binary at binaryPath
+func NewSymbolizer(binaryPath string) (*Symbolizer, error) {
+	f, err := elf.Open(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pclntabSection := f.Section(".gopclntab")
+	if pclntabSection == nil {
+		return nil, fmt.Errorf("%s has no .gopclntab section, is it a Go binary ?", binaryPath)
+	}
+	pclntab, err := pclntabSection.Data()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read .gopclntab of %s: %s", binaryPath, err)
+	}
+
+	textSection := f.Section(".text")
+	if textSection == nil {
+		return nil, fmt.Errorf("%s has no .text section", binaryPath)
+	}
+
+	var symtab []byte
+	if symtabSection := f.Section(".gosymtab"); symtabSection != nil {
+		if symtab, err = symtabSection.Data(); err != nil {
+			return nil, fmt.Errorf("cannot read .gosymtab of %s: %s", binaryPath, err)
+		}
+	}
+
+	table, err := gosym.NewTable(symtab, gosym.NewLineTable(pclntab, textSection.Addr))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse symbol table of %s: %s", binaryPath, err)
+	}
+
+	return &Symbolizer{table: table}, nil
+}
+
+//SymbolizePC resolves pc. ok is false if pc is not in the binary.
+//Like runtime.CallersFrames, pc is taken as a return address (as the PCs of runtime.Callers and of stack traces), so
+//the line is the one of the call preceding it. Add 1 to the PC of the faulting instruction of a leaf frame.
+func (s *Symbolizer) SymbolizePC(pc uint64) (item StackTraceItem, ok bool) {
+	fn := s.table.PCToFunc(pc)
+	if fn == nil {
+		return StackTraceItem{}, false
+	}
+	lookupPC := pc
+	if pc > fn.Entry {
+		lookupPC-- // return address is after the call instruction
+	}
+	file, line, _ := s.table.PCToLine(lookupPC)
+
+	return StackTraceItem{
+		CallingObject: fn.Name,
+		SourcePathRef: file,
+		SourceLineRef: line,
+		MysteryNumber: int64(pc - fn.Entry),
+		PCOffset:      int64(pc - fn.Entry),
+		PC:            uintptr(pc),
+		Entry:         uintptr(fn.Entry),
+	}, true
+}
+
+//SymbolizeFunc resolves the PC at offset bytes from the entry of function, as found in stack traces (eg: main.main+0x1a).
+//As such offsets are return addresses, the line is the one of the call preceding it.
+func (s *Symbolizer) SymbolizeFunc(function string, offset uint64) (item StackTraceItem, ok bool) {
+	fn := s.table.LookupFunc(function)
+	if fn == nil {
+		return StackTraceItem{}, false
+	}
+
+	pc := fn.Entry + offset
+	lookupPC := pc
+	if offset > 0 {
+		lookupPC-- // return address is after the call instruction
+	}
+	file, line, _ := s.table.PCToLine(lookupPC)
+
+	return StackTraceItem{
+		CallingObject: fn.Name,
+		SourcePathRef: file,
+		SourceLineRef: line,
+		MysteryNumber: int64(offset),
+		PCOffset:      int64(offset),
+		PC:            uintptr(pc),
+		Entry:         uintptr(fn.Entry),
+	}, true
+}
+
+//SymbolizeText resolves every address found in text, innermost frame first, which can be:
+//one PC per line (0x45a1b2), functions with offsets (main.main+0x1a), or a goroutine stack trace
+//whose "+0x1a" offsets are resolved even if file and line are missing or wrong.
+//It returns an error for addresses which cannot be resolved, along with the items that could.
+func (s *Symbolizer) SymbolizeText(text string) ([]StackTraceItem, error) {
+	var (
+		items      []StackTraceItem
+		unresolved []string
+		function   string // function of the last function line of a goroutine stack trace
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+
+		if m := regexpSymbolizeFileLine.FindStringSubmatch(line); m != nil && function != "" {
+			offset, _ := strconv.ParseUint(m[1], 16, 64)
+			if item, ok := s.SymbolizeFunc(function, offset); ok {
+				items = append(items, item)
+			} else {
+				unresolved = append(unresolved, fmt.Sprintf("%s+0x%s", function, m[1]))
+			}
+			function = ""
+			continue
+		}
+		function = ""
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case regexpSymbolizePC.MatchString(trimmed):
+			pc, _ := strconv.ParseUint(regexpSymbolizePC.FindStringSubmatch(trimmed)[1], 16, 64)
+			if item, ok := s.SymbolizePC(pc); ok {
+				items = append(items, item)
+			} else {
+				unresolved = append(unresolved, trimmed)
+			}
+		case regexpSymbolizeFuncOffset.MatchString(trimmed):
+			m := regexpSymbolizeFuncOffset.FindStringSubmatch(trimmed)
+			offset, _ := strconv.ParseUint(m[2], 16, 64)
+			if item, ok := s.SymbolizeFunc(m[1], offset); ok {
+				items = append(items, item)
+			} else {
+				unresolved = append(unresolved, trimmed)
+			}
+		case regexpSymbolizeFuncLine.MatchString(line):
+			function = regexpSymbolizeFuncLine.FindStringSubmatch(line)[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return items, err
+	}
+
+	if len(unresolved) > 0 {
+		return items, fmt.Errorf("cannot resolve %d addresses: %s", len(unresolved), strings.Join(unresolved, ", "))
+	}
+	return items, nil
+}
@@ -0,0 +1,40 @@
+package errlog
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestSymbolizer(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("symbolizing needs an ELF binary")
+	}
+	binary, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	s, err := NewSymbolizer(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pc, file, line, _ := runtime.Caller(0)
+	fn := runtime.FuncForPC(pc)
+	offset := uint64(pc - fn.Entry())
+
+	text := fmt.Sprintf("%#x\n%s+%#x\n%s()\n\t?:0 +%#x\n0x1\n", pc, fn.Name(), offset, fn.Name(), offset)
+	items, err := s.SymbolizeText(text)
+	if err == nil {
+		t.Error("SymbolizeText() did not report unresolved address 0x1")
+	}
+	if len(items) != 3 {
+		t.Fatalf("SymbolizeText() returned %d items, want 3", len(items))
+	}
+	for i, item := range items {
+		if item.CallingObject != fn.Name() || item.SourcePathRef != file || item.SourceLineRef != line {
+			t.Errorf("item %d = %s (%s:%d), want %s (%s:%d)", i, item.CallingObject, item.SourcePathRef, item.SourceLineRef, fn.Name(), file, line)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/snwfdhmp/errlog"
+)
+
+func main() {
+	jobs := []string{"a", "b"}
+
+	run := func(job string) {
+		err := process(job)
+		if errlog.Debug(err) {
+			return
+		}
+	}
+
+	for _, job := range jobs {
+		run(job)
+	}
+}
+
+func process(job string) error {
+	return errors.New("cannot process job " + job)
+}
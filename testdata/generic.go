@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/snwfdhmp/errlog"
+)
+
+func main() {
+	_, _ = First([]int{})
+}
+
+func First[E any](s []E) (E, error) {
+	var zero E
+
+	err := checkLength(len(s))
+	if errlog.Debug(err) {
+		return zero, err
+	}
+
+	return s[0], nil
+}
+
+func checkLength(n int) error {
+	if n == 0 {
+		return fmt.Errorf("empty slice")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/snwfdhmp/errlog"
+)
+
+type Store struct {
+	path string
+}
+
+func main() {
+	s := &Store{path: "/var/lib/store"}
+	s.Save(42, "hello")
+}
+
+func (s *Store) Save(id int, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	err := s.write(id, name)
+	if errlog.Debug(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) write(id int, name string) error {
+	return errors.New("read-only file system")
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/snwfdhmp/errlog"
+)
+
+func main() {
+	loadConfig("config.json")
+}
+
+func loadConfig(path string) {
+	err := readFile(
+		path,
+		0644,
+	)
+	if errlog.Debug(
+		err,
+	) {
+		return
+	}
+}
+
+func readFile(path string, mode int) error {
+	return errors.New("no such file or directory")
+}
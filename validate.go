@@ -0,0 +1,161 @@
+package errlog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+//Severity tells how bad a problem found by Validate is
+type Severity int
+
+const (
+	//SeverityWarning is a problem errlog can live with, but which is likely not what was meant
+	SeverityWarning Severity = iota + 1
+	//SeverityError is an invalid value, which errlog does not handle as is
+	SeverityError
+)
+
+//String returns the name of the severity (eg: warning)
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+//Diagnostic is a problem of a Config found by Validate
+type Diagnostic struct {
+	Field        string   //Name of the Config field (eg: LinesBefore)
+	Severity     Severity //How bad it is
+	Message      string   //What is wrong
+	SuggestedFix string   //How to fix it
+	fix          func(cfg *Config)
+}
+
+//AutoFixable tells whether AutoFix fixes the problem
+func (d Diagnostic) AutoFixable() bool {
+	return d.fix != nil
+}
+
+//String returns the diagnostic on one line (eg: error: LinesBefore: is -1, should not be < 0 (set it to 0))
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", d.Severity, d.Field, d.Message, d.SuggestedFix)
+}
+
+//Validate returns the problems of cfg, without modifying it. Problems having an automatic fix can be fixed in a copy
+//of cfg with AutoFix.
+func (cfg *Config) Validate() []Diagnostic {
+	return cfg.validate(true)
+}
+
+//validate returns the problems of cfg. Checking sources is slower, and their problems have no automatic fix.
+func (cfg *Config) validate(checkSources bool) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	if cfg.PrintFunc == nil {
+		diagnostics = append(diagnostics, Diagnostic{
+			Field:        "PrintFunc",
+			Severity:     SeverityWarning,
+			Message:      "is not set, nothing can be printed",
+			SuggestedFix: "set it to DefaultLoggerPrintFunc",
+			fix:          func(cfg *Config) { cfg.PrintFunc = DefaultLoggerPrintFunc },
+		})
+	}
+
+	if cfg.Mode != 0 && !isIntInSlice(cfg.Mode, enabledModes) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Field:        "Mode",
+			Severity:     SeverityError,
+			Message:      fmt.Sprintf("is %d, which is neither ModeEnabled nor ModeDisabled", cfg.Mode),
+			SuggestedFix: "set it to ModeEnabled",
+			fix:          func(cfg *Config) { cfg.Mode = ModeEnabled },
+		})
+	}
+
+	if _, ok := levelNames[cfg.Level]; cfg.Level != 0 && !ok {
+		diagnostics = append(diagnostics, Diagnostic{
+			Field:        "Level",
+			Severity:     SeverityError,
+			Message:      fmt.Sprintf("is %d, which is not a level", int(cfg.Level)),
+			SuggestedFix: "set it to 0 to use LevelError",
+			fix:          func(cfg *Config) { cfg.Level = 0 },
+		})
+	}
+
+	for _, field := range []struct {
+		name  string
+		value func(cfg *Config) *int
+	}{
+		{"LinesBefore", func(cfg *Config) *int { return &cfg.LinesBefore }},
+		{"LinesAfter", func(cfg *Config) *int { return &cfg.LinesAfter }},
+		{"StackSourceDepth", func(cfg *Config) *int { return &cfg.StackSourceDepth }},
+		{"StackSourceLines", func(cfg *Config) *int { return &cfg.StackSourceLines }},
+		{"VarsMaxDepth", func(cfg *Config) *int { return &cfg.VarsMaxDepth }},
+		{"VarsMaxItems", func(cfg *Config) *int { return &cfg.VarsMaxItems }},
+	} {
+		value := field.value
+		if *value(cfg) < 0 {
+			diagnostics = append(diagnostics, Diagnostic{
+				Field:        field.name,
+				Severity:     SeverityError,
+				Message:      fmt.Sprintf("is %d, should not be < 0", *value(cfg)),
+				SuggestedFix: "set it to 0",
+				fix:          func(cfg *Config) { *value(cfg) = 0 },
+			})
+		}
+	}
+
+	if checkSources && (cfg.PrintSource || cfg.PrintStackSource) {
+		if file, readable := callerSource(); !readable {
+			diagnostics = append(diagnostics, Diagnostic{
+				Field:    "PrintSource",
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("is enabled, but sources are not readable (eg: %s)", file),
+				SuggestedFix: "run the program where its sources are, built without -trimpath, " +
+					"or disable PrintSource and PrintStackSource",
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+//AutoFix returns a copy of cfg where the problems having an automatic fix are fixed, and the fixed problems.
+//cfg is not modified, and nothing is fixed unless AutoFix is called: NewLogger and SetConfig use configs as they are.
+//Problems left to fix by hand are returned by Validate.
+func (cfg *Config) AutoFix() (fixedCfg *Config, fixed []Diagnostic) {
+	c := *cfg
+	for _, d := range c.validate(false) {
+		if d.AutoFixable() {
+			d.fix(&c)
+			fixed = append(fixed, d)
+		}
+	}
+	return &c, fixed
+}
+
+//callerSource returns the source file of the first caller outside of errlog, and whether it can be read
+func callerSource() (file string, readable bool) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePath+".") && !strings.HasPrefix(frame.Function, "runtime.") {
+			_, err := fs.Stat(frame.File)
+			return frame.File, err == nil
+		}
+		if !more {
+			return "", true
+		}
+	}
+}
+
+//packagePath is the import path of this package (eg: github.com/snwfdhmp/errlog)
+var packagePath = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	return packageOf(runtime.FuncForPC(pc).Name())
+}()
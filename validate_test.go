@@ -0,0 +1,73 @@
+package errlog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestValidate(t *testing.T) {
+	defer func(osFs afero.Fs) { fs = osFs }(fs)
+	fs = afero.NewMemMapFs() // no source is readable
+
+	cfg := &Config{Mode: 42, LinesBefore: -1, PrintSource: true}
+	diagnostics := cfg.Validate()
+
+	var fields []string
+	for _, d := range diagnostics {
+		fields = append(fields, d.Field)
+	}
+	if want := []string{"PrintFunc", "Mode", "LinesBefore", "PrintSource"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("Validate() found problems with %v, want %v", fields, want)
+	}
+	if cfg.Mode != 42 || cfg.LinesBefore != -1 || cfg.PrintFunc != nil {
+		t.Errorf("Validate() modified the config: %+v", cfg)
+	}
+	if d := diagnostics[2]; d.Severity != SeverityError || d.String() != "error: LinesBefore: is -1, should not be < 0 (set it to 0)" {
+		t.Errorf("unexpected diagnostic %q", d)
+	}
+
+	fixedCfg, fixed := cfg.AutoFix()
+	if len(fixed) != 3 {
+		t.Errorf("AutoFix() fixed %d problems, want 3", len(fixed))
+	}
+	if fixedCfg.Mode != ModeEnabled || fixedCfg.LinesBefore != 0 || fixedCfg.PrintFunc == nil {
+		t.Errorf("AutoFix() did not fix the config: %+v", fixedCfg)
+	}
+	if cfg.Mode != 42 || cfg.LinesBefore != -1 || cfg.PrintFunc != nil {
+		t.Errorf("AutoFix() modified the config: %+v", cfg)
+	}
+	if remaining := fixedCfg.Validate(); len(remaining) != 1 || remaining[0].AutoFixable() {
+		t.Errorf("Validate() after AutoFix() = %v, want the PrintSource warning", remaining)
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	printed := 0
+	cfg := &Config{
+		PrintFunc:   func(format string, data ...interface{}) { printed++ },
+		Mode:        42,
+		LinesBefore: -1,
+		PrintError:  true,
+		PrintSource: true,
+	}
+	l := NewLogger(cfg).(*logger)
+	if l.Config() != cfg || cfg.Mode != 42 || cfg.LinesBefore != -1 {
+		t.Fatalf("NewLogger() modified the config: %+v", cfg)
+	}
+
+	l.Debug(errors.New("failed"))
+	if printed == 0 {
+		t.Error("Debug() printed nothing")
+	}
+
+	if !l.Doctor() || l.Config() != cfg || cfg.Mode != 42 || cfg.LinesBefore != -1 {
+		t.Errorf("Doctor() did not only report the problems of the config: %+v", cfg)
+	}
+	l.SetConfig(&Config{PrintFunc: cfg.PrintFunc})
+	if l.Doctor() {
+		t.Error("Doctor() found problems in a valid config")
+	}
+}
@@ -0,0 +1,302 @@
+package errlog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultVarsMaxDepth  = 3  //how deep nested values are printed
+	defaultVarsMaxItems  = 10 //how many items of slices, arrays and maps are printed
+	defaultVarsMaxString = 80 //how many bytes of strings are printed
+)
+
+//Var is a named value recorded with DebugVars or DebugMap, already formatted
+type Var struct {
+	Name  string //Name given to the value
+	Value string //Value, formatted with the depth and size limits of the logger config
+}
+
+//varsFormatter formats values for printing, with limits on depth and size
+type varsFormatter struct {
+	maxDepth  int
+	maxItems  int
+	maxString int
+	visiting  map[varsPointer]bool //pointers being formatted, as they do not count in depth (eg: x = &x)
+}
+
+//varsPointer identifies a pointer being formatted. A struct and its first field have the same address, not type.
+type varsPointer struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+//newVarsFormatter creates a varsFormatter with the limits of cfg
+func newVarsFormatter(cfg *Config) *varsFormatter {
+	f := &varsFormatter{
+		maxDepth:  cfg.VarsMaxDepth,
+		maxItems:  cfg.VarsMaxItems,
+		maxString: defaultVarsMaxString,
+	}
+	if f.maxDepth < 1 {
+		f.maxDepth = defaultVarsMaxDepth
+	}
+	if f.maxItems < 1 {
+		f.maxItems = defaultVarsMaxItems
+	}
+	return f
+}
+
+//formatKeyvals formats alternated names and values, as given to DebugVars
+func (f *varsFormatter) formatKeyvals(keyvals []interface{}) []Var {
+	vars := make([]Var, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		name := fmt.Sprint(keyvals[i])
+		if i+1 >= len(keyvals) {
+			vars = append(vars, Var{Name: name, Value: "<missing value>"})
+			break
+		}
+		vars = append(vars, Var{Name: name, Value: f.format(keyvals[i+1])})
+	}
+	return vars
+}
+
+//mapToKeyvals returns the content of vars as alternated names and values, sorted by name
+func mapToKeyvals(vars map[string]interface{}) []interface{} {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keyvals := make([]interface{}, 0, 2*len(vars))
+	for _, name := range names {
+		keyvals = append(keyvals, name, vars[name])
+	}
+	return keyvals
+}
+
+//format formats v. Struct fields tagged `errlog:"secret"` are masked.
+func (f *varsFormatter) format(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+	var b strings.Builder
+	f.formatValue(&b, reflect.ValueOf(v), 0)
+	return b.String()
+}
+
+func (f *varsFormatter) formatValue(b *strings.Builder, v reflect.Value, depth int) {
+	if formatted, panicked, ok := formatWithMethod(v); ok {
+		if panicked {
+			b.WriteString(formatted)
+			return
+		}
+		f.formatString(b, formatted)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		b.WriteString("nil")
+	case reflect.Bool:
+		fmt.Fprintf(b, "%t", v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fmt.Fprintf(b, "%d", v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		fmt.Fprintf(b, "%d", v.Uint())
+	case reflect.Float32, reflect.Float64:
+		fmt.Fprintf(b, "%g", v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		fmt.Fprintf(b, "%g", v.Complex())
+	case reflect.String:
+		f.formatString(b, v.String())
+	case reflect.Ptr:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		p := varsPointer{v.Type(), v.Pointer()}
+		if f.visiting[p] {
+			b.WriteString("<cycle>")
+			return
+		}
+		if f.visiting == nil {
+			f.visiting = make(map[varsPointer]bool)
+		}
+		f.visiting[p] = true
+		defer delete(f.visiting, p)
+
+		b.WriteString("&")
+		f.formatValue(b, v.Elem(), depth)
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		f.formatValue(b, v.Elem(), depth)
+	case reflect.Slice:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			fmt.Fprintf(b, "%s(len %d)", v.Type(), v.Len())
+			return
+		}
+		f.formatList(b, v, depth)
+	case reflect.Array:
+		f.formatList(b, v, depth)
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		f.formatMap(b, v, depth)
+	case reflect.Struct:
+		f.formatStruct(b, v, depth)
+	default: // chan, func, unsafe pointer
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		fmt.Fprintf(b, "%s(%#x)", v.Type(), v.Pointer())
+	}
+}
+
+//formatString quotes s, truncated to maxString bytes
+func (f *varsFormatter) formatString(b *strings.Builder, s string) {
+	if len(s) <= f.maxString {
+		fmt.Fprintf(b, "%q", s)
+		return
+	}
+	fmt.Fprintf(b, "%q... (len %d)", s[:f.maxString], len(s))
+}
+
+//formatList formats slices and arrays, truncated to maxItems items
+func (f *varsFormatter) formatList(b *strings.Builder, v reflect.Value, depth int) {
+	b.WriteString(v.Type().String())
+	if depth >= f.maxDepth {
+		fmt.Fprintf(b, "{...} (len %d)", v.Len())
+		return
+	}
+
+	b.WriteString("{")
+	for i := 0; i < v.Len() && i < f.maxItems; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		f.formatValue(b, v.Index(i), depth+1)
+	}
+	if v.Len() > f.maxItems {
+		fmt.Fprintf(b, ", ... +%d more", v.Len()-f.maxItems)
+	}
+	b.WriteString("}")
+}
+
+//formatMap formats maps sorted by formatted keys, truncated to maxItems items
+func (f *varsFormatter) formatMap(b *strings.Builder, v reflect.Value, depth int) {
+	b.WriteString(v.Type().String())
+	if depth >= f.maxDepth {
+		fmt.Fprintf(b, "{...} (len %d)", v.Len())
+		return
+	}
+
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		var kb strings.Builder
+		f.formatValue(&kb, key, depth+1)
+		entries = append(entries, entry{key: kb.String(), value: v.MapIndex(key)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	b.WriteString("{")
+	for i := 0; i < len(entries) && i < f.maxItems; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(entries[i].key)
+		b.WriteString(": ")
+		f.formatValue(b, entries[i].value, depth+1)
+	}
+	if len(entries) > f.maxItems {
+		fmt.Fprintf(b, ", ... +%d more", len(entries)-f.maxItems)
+	}
+	b.WriteString("}")
+}
+
+//formatStruct formats structs, masking fields tagged `errlog:"secret"`
+func (f *varsFormatter) formatStruct(b *strings.Builder, v reflect.Value, depth int) {
+	b.WriteString(v.Type().String())
+	if depth >= f.maxDepth {
+		b.WriteString("{...}")
+		return
+	}
+
+	b.WriteString("{")
+	for i := 0; i < v.NumField(); i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		field := v.Type().Field(i)
+		b.WriteString(field.Name)
+		b.WriteString(": ")
+		if isSecretField(field) {
+			b.WriteString("<secret>")
+			continue
+		}
+		f.formatValue(b, v.Field(i), depth+1)
+	}
+	b.WriteString("}")
+}
+
+//formatWithMethod formats values (not pointers) implementing error or fmt.Stringer with their method, such as time.Time.
+//Structs with secret fields are never formatted this way. If the method panics, formatted is <panic in String: value>.
+func formatWithMethod(v reflect.Value) (formatted string, panicked, ok bool) {
+	if !v.IsValid() || !v.CanInterface() || v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return "", false, false
+	}
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			if isSecretField(v.Type().Field(i)) {
+				return "", false, false
+			}
+		}
+	}
+
+	switch i := v.Interface().(type) {
+	case error:
+		formatted, panicked = callFormatMethod("Error", i.Error)
+		return formatted, panicked, true
+	case fmt.Stringer:
+		formatted, panicked = callFormatMethod("String", i.String)
+		return formatted, panicked, true
+	}
+	return "", false, false
+}
+
+//callFormatMethod returns the result of method, or <panic in name: value> if it panics, like fmt does
+func callFormatMethod(name string, method func() string) (s string, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			s, panicked = fmt.Sprintf("<panic in %s: %v>", name, r), true
+		}
+	}()
+	return method(), false
+}
+
+//isSecretField tells whether field is tagged `errlog:"secret"`
+func isSecretField(field reflect.StructField) bool {
+	for _, opt := range strings.Split(field.Tag.Get("errlog"), ",") {
+		if opt == "secret" {
+			return true
+		}
+	}
+	return false
+}
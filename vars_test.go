@@ -0,0 +1,76 @@
+package errlog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+//panickingStringer is a fmt.Stringer whose String method panics
+type panickingStringer struct{}
+
+func (panickingStringer) String() string { panic("boom") }
+
+func TestVarsFormatter(t *testing.T) {
+	type credentials struct {
+		User     string
+		Password string `errlog:"secret"`
+	}
+	type request struct {
+		ID    int
+		Tags  []string
+		Creds *credentials
+		At    time.Time
+	}
+
+	type selfPointer *selfPointer
+	var self selfPointer
+	self = &self
+	var cyclic interface{}
+	cyclic = &cyclic
+	shared := new(int)
+
+	f := newVarsFormatter(&Config{VarsMaxDepth: 2, VarsMaxItems: 2})
+
+	cases := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, "nil"},
+		{"int", 42, "42"},
+		{"string", "hello", `"hello"`},
+		{"long string", string(make([]byte, 100)), `"` + strings.Repeat(`\x00`, 80) + `"... (len 100)`},
+		{"bytes", []byte("hello"), "[]uint8(len 5)"},
+		{"truncated slice", []int{1, 2, 3}, "[]int{1, 2, ... +1 more}"},
+		{"sorted map", map[string]int{"b": 2, "a": 1}, `map[string]int{"a": 1, "b": 2}`},
+		{"stringer", time.Unix(0, 0).UTC(), `"1970-01-01 00:00:00 +0000 UTC"`},
+		{
+			"secret and depth",
+			&request{ID: 1, Tags: []string{"x"}, Creds: &credentials{User: "bob", Password: "hunter2"}},
+			`&errlog.request{ID: 1, Tags: []string{"x"}, Creds: &errlog.credentials{User: "bob", Password: <secret>}, At: "0001-01-01 00:00:00 +0000 UTC"}`,
+		},
+		{"max depth", [][][]int{{{1}}}, "[][][]int{[][]int{[]int{...} (len 1)}}"},
+		{"panicking stringer", panickingStringer{}, "<panic in String: boom>"},
+		{"pointer cycle", self, "&<cycle>"},
+		{"interface cycle", cyclic, "&<cycle>"},
+		{"same pointer twice", []*int{shared, shared}, "[]*int{&0, &0}"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := f.format(c.value); got != c.want {
+				t.Errorf("format(%#v) =\n%s\nwant\n%s", c.value, got, c.want)
+			}
+		})
+	}
+}
+
+func TestFormatKeyvals(t *testing.T) {
+	f := newVarsFormatter(&Config{})
+	got := f.formatKeyvals([]interface{}{"userID", 42, "odd"})
+	want := []Var{{Name: "userID", Value: "42"}, {Name: "odd", Value: "<missing value>"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("formatKeyvals() = %v, want %v", got, want)
+	}
+}